@@ -1,67 +1,153 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/danonenka/PR-service/internal/auth"
+	"github.com/danonenka/PR-service/internal/config"
 	httphandler "github.com/danonenka/PR-service/internal/delivery/http"
-	"github.com/danonenka/PR-service/internal/repository/postgres"
+	"github.com/danonenka/PR-service/internal/delivery/http/handlers"
+	"github.com/danonenka/PR-service/internal/delivery/http/middleware"
+	"github.com/danonenka/PR-service/internal/logging"
+	"github.com/danonenka/PR-service/internal/metrics"
+	"github.com/danonenka/PR-service/internal/migration"
 	"github.com/danonenka/PR-service/internal/usecase"
+	"github.com/danonenka/PR-service/internal/webhook"
+	"github.com/danonenka/PR-service/migrations"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/lib/pq"
 )
 
 func main() {
-	dbHost := getEnv("DB_HOST", "localhost")
-	dbPort := getEnv("DB_PORT", "5432")
-	dbUser := getEnv("DB_USER", "postgres")
-	dbPassword := getEnv("DB_PASSWORD", "postgres")
-	dbName := getEnv("DB_NAME", "pr_service")
-	dbSSLMode := getEnv("DB_SSLMODE", "disable")
-
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		dbHost, dbPort, dbUser, dbPassword, dbName, dbSSLMode)
-
-	db, err := sql.Open("postgres", dsn)
+	cfg, args, err := config.Load(os.Args[1:], os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		fatal("Invalid configuration", err)
 	}
-	defer db.Close()
+	logLevel, _ := logging.ParseLevel(cfg.Log.Level)
+	slog.SetDefault(logging.NewLogger(os.Stdout, logLevel))
 
-	if err := db.Ping(); err != nil {
-		log.Fatalf("Failed to ping database: %v", err)
+	// pr-service config печатает действующую конфигурацию без секретов
+	if len(args) > 0 && args[0] == "config" {
+		if err := cfg.Dump(os.Stdout); err != nil {
+			fatal("Failed to print configuration", err)
+		}
+		return
 	}
 
-	userRepo := postgres.NewUserRepository(db)
-	teamRepo := postgres.NewTeamRepository(db)
-	prRepo := postgres.NewPullRequestRepository(db)
-	assignmentRepo := postgres.NewReviewerAssignmentRepository(db)
+	// SIGINT/SIGTERM отменяют ctx: сервер перестаёт принимать запросы,
+	// дорабатывает текущие и останавливает диспетчер вебхуков
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	reassignmentUsecase := usecase.NewReassignmentUsecase(prRepo, userRepo, assignmentRepo)
-	userUsecase := usecase.NewUserUsecase(userRepo, teamRepo, reassignmentUsecase)
-	teamUsecase := usecase.NewTeamUsecase(teamRepo, userRepo)
-	prUsecase := usecase.NewPRUsecase(prRepo, userRepo, assignmentRepo)
-	statisticsUsecase := usecase.NewStatisticsUsecase(prRepo, assignmentRepo, userRepo)
+	var (
+		db     *sql.DB
+		repos  *repositories
+		checks = make(map[string]handlers.ReadinessCheck)
+	)
+	if cfg.Storage == config.StorageMemory {
+		if len(args) > 0 {
+			fatal("Unknown command", fmt.Errorf("%q is not available with STORAGE=memory", args[0]))
+		}
+		slog.Warn("Using in-memory storage, all data is lost on shutdown")
+		repos = newMemoryRepositories()
+	} else {
+		db, err = openDatabase(cfg.Database)
+		if err != nil {
+			fatal("Failed to connect to database", err)
+		}
+		defer db.Close()
 
-	router := httphandler.NewRouter(userUsecase, teamUsecase, prUsecase, statisticsUsecase)
+		migrator, err := migration.NewMigrator(db, migrations.FS)
+		if err != nil {
+			fatal("Failed to load migrations", err)
+		}
+		if len(args) > 0 {
+			if args[0] != "migrate" {
+				fatal("Unknown command", fmt.Errorf("%q, %s", args[0], migrateUsage))
+			}
+			if err := runMigrate(ctx, migrator, args[1:]); err != nil {
+				fatal("Migration failed", err)
+			}
+			return
+		}
 
-	gin.SetMode(gin.ReleaseMode)
-	engine := gin.Default()
+		// Без MIGRATE_ON_START схему обновляют заранее: pr-service migrate up
+		if cfg.Database.MigrateOnStart {
+			if _, err := migrator.Up(ctx); err != nil {
+				fatal("Failed to apply migrations", err)
+			}
+		}
+		if err := migrator.Check(ctx); err != nil {
+			fatal("Database schema is not up to date, run `pr-service migrate up` or set MIGRATE_ON_START=true", err)
+		}
+
+		repos = newPostgresRepositories(db)
+		checks["database"] = db.PingContext
+		checks["migrations"] = migrator.Check
+	}
+
+	reviewerService := usecase.NewReviewerService()
+
+	reassignmentUsecase := usecase.NewReassignmentUsecase(repos.prs, repos.txManager, reviewerService)
+	reassignmentUsecase.SetBatchLimits(cfg.Reassignment.Workers, cfg.Reassignment.Deadline)
+	userUsecase := usecase.NewUserUsecase(repos.users, repos.teams, reassignmentUsecase)
+	teamUsecase := usecase.NewTeamUsecase(repos.teams, repos.users, repos.txManager)
+	teamUsecase.SetDefaults(cfg.Reviewers.Strategy, cfg.Reviewers.MinReviewers, cfg.Reviewers.MaxReviewers)
+	prUsecase := usecase.NewPRUsecase(repos.prs, repos.users, repos.teams, repos.assignments, repos.events, repos.txManager, reviewerService)
+	statisticsUsecase := usecase.NewStatisticsUsecase(repos.stats, repos.teams)
+	webhookUsecase := usecase.NewWebhookUsecase(repos.webhooks, repos.deliveries, repos.teams)
+	githubUsecase := usecase.NewGitHubUsecase(repos.identities, repos.inbound, repos.users, prUsecase)
 
-	engine.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	// Без базы (STORAGE=memory) метрик пула соединений нет
+	serviceMetrics := metrics.New(db)
+	prUsecase.SetMetrics(serviceMetrics)
+	reassignmentUsecase.SetMetrics(serviceMetrics)
+
+	authenticator, err := newAuthenticator(cfg.Auth)
+	if err != nil {
+		fatal("Failed to configure authentication", err)
+	}
+
+	// Диспетчер разбирает outbox вебхуков в фоне
+	dispatcher := webhook.NewDispatcher(repos.deliveries, repos.webhooks, webhook.Config{
+		BatchSize:   cfg.Webhooks.BatchSize,
+		MaxAttempts: cfg.Webhooks.MaxAttempts,
+		BaseBackoff: cfg.Webhooks.BaseBackoff,
+		MaxBackoff:  cfg.Webhooks.MaxBackoff,
+		Timeout:     cfg.Webhooks.Timeout,
 	})
+	dispatcherDone := make(chan struct{})
+	go func() {
+		defer close(dispatcherDone)
+		dispatcher.Run(ctx, cfg.Webhooks.PollInterval)
+	}()
+
+	router := httphandler.NewRouter(userUsecase, teamUsecase, prUsecase, statisticsUsecase, webhookUsecase, githubUsecase, cfg.GitHub.WebhookSecret, authenticator)
+
+	gin.SetMode(gin.ReleaseMode)
+	engine := gin.New()
+	engine.Use(middleware.RequestID(), middleware.AccessLog(), middleware.RequestMetrics(serviceMetrics), middleware.Recovery())
+
+	health := handlers.NewHealthHandler(checks)
+	engine.GET("/livez", health.Livez)
+	engine.GET("/readyz", health.Readyz)
+	engine.GET("/metrics", gin.WrapH(serviceMetrics.Handler()))
 
 	// Swagger UI
-	openapiPath := "/app/openapi.yaml"
-	if _, err := os.Stat("./openapi.yaml"); err == nil {
-		openapiPath = "./openapi.yaml"
-	}
-	engine.StaticFile("/swagger/openapi.yaml", openapiPath)
+	engine.StaticFile("/swagger/openapi.yaml", cfg.Server.OpenAPIPath)
 
 	// Swagger UI HTML
 	engine.GET("/swagger-ui", func(c *gin.Context) {
@@ -93,18 +179,85 @@ func main() {
 
 	router.SetupRoutes(engine)
 
-	port := getEnv("PORT", "8080")
-	log.Printf("Server starting on 0.0.0.0:%s", port)
-	log.Printf("Swagger UI available at http://localhost:%s/swagger-ui", port)
-	log.Printf("OpenAPI spec available at http://localhost:%s/swagger/openapi.yaml", port)
-	if err := engine.Run("0.0.0.0:" + port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	server := &http.Server{
+		Addr:              cfg.Server.Addr,
+		Handler:           engine,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+	shutdownTimeout := cfg.Server.ShutdownTimeout
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+	slog.Info("Server starting",
+		"addr", server.Addr,
+		"config_file", cfg.File,
+		"storage", cfg.Storage,
+		"log_level", logLevel.String())
+
+	select {
+	case err := <-serverErr:
+		fatal("Failed to start server", err)
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down", "timeout", shutdownTimeout.String())
+	health.SetDraining()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	// Shutdown закрывает слушатель и ждёт завершения текущих запросов;
+	// по истечении срока оставшиеся соединения обрываются
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Graceful shutdown timed out, closing remaining connections", "error", err)
+		_ = server.Close()
+	}
+	select {
+	case <-dispatcherDone:
+	case <-shutdownCtx.Done():
+		slog.Warn("Webhook dispatcher did not stop before shutdown deadline")
 	}
+	if err := <-serverErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("Server stopped with error", "error", err)
+	}
+	slog.Info("Server stopped")
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// newAuthenticator собирает аутентификатор из секции auth конфигурации.
+// Disabled отключает аутентификацию — только для локальной отладки.
+func newAuthenticator(cfg config.AuthConfig) (*auth.Authenticator, error) {
+	if cfg.Disabled {
+		slog.Warn("Authentication is disabled, all endpoints are open")
+		return nil, nil
+	}
+
+	tokens, err := auth.ParseStaticTokens(cfg.APITokens)
+	if err != nil {
+		return nil, err
+	}
+	authConfig := auth.Config{
+		StaticTokens: tokens,
+		HS256Secret:  []byte(cfg.JWTHS256Secret),
+		Issuer:       cfg.JWTIssuer,
+		Audience:     cfg.JWTAudience,
+	}
+	if cfg.JWTRS256PublicKeyFile != "" {
+		pem, err := os.ReadFile(cfg.JWTRS256PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if authConfig.RS256PublicKey, err = auth.ParseRSAPublicKey(pem); err != nil {
+			return nil, err
+		}
 	}
-	return defaultValue
+	return auth.NewAuthenticator(authConfig)
 }
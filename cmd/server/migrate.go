@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/danonenka/PR-service/internal/migration"
+)
+
+const migrateUsage = "usage: pr-service migrate up | down [N] | status | goto N"
+
+// runMigrate выполняет подкоманду migrate: up применяет все миграции,
+// down откатывает N последних (по умолчанию одну), goto переводит схему
+// на версию N (0 — откатить всё), status печатает текущую версию.
+func runMigrate(ctx context.Context, migrator *migration.Migrator, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("applied %d migrations\n", applied)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("down: N must be a positive integer")
+			}
+			steps = n
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("reverted %d migrations\n", reverted)
+	case "goto":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("goto: N must be a migration version")
+		}
+		steps, err := migrator.Goto(ctx, uint(version))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("migrated to version %d in %d steps\n", version, steps)
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("version: %d\nlatest: %d\ndirty: %t\n", status.Current, status.Latest, status.Dirty)
+		for _, pending := range status.Pending {
+			fmt.Printf("pending: %06d_%s\n", pending.Version, pending.Name)
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+	return nil
+}
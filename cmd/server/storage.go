@@ -0,0 +1,77 @@
+package main
+
+import (
+	"database/sql"
+
+	"github.com/danonenka/PR-service/internal/config"
+	"github.com/danonenka/PR-service/internal/domain"
+	"github.com/danonenka/PR-service/internal/repository/memory"
+	"github.com/danonenka/PR-service/internal/repository/postgres"
+)
+
+// repositories — реализации репозиториев выбранного хранилища (STORAGE).
+type repositories struct {
+	users       domain.UserRepository
+	teams       domain.TeamRepository
+	prs         domain.PullRequestRepository
+	assignments domain.ReviewerAssignmentRepository
+	stats       domain.StatisticsRepository
+	events      domain.PREventRepository
+	webhooks    domain.WebhookSubscriptionRepository
+	deliveries  domain.WebhookDeliveryRepository
+	identities  domain.IdentityRepository
+	inbound     domain.InboundDeliveryRepository
+	txManager   domain.TxManager
+}
+
+func newPostgresRepositories(db *sql.DB) *repositories {
+	return &repositories{
+		users:       postgres.NewUserRepository(db),
+		teams:       postgres.NewTeamRepository(db),
+		prs:         postgres.NewPullRequestRepository(db),
+		assignments: postgres.NewReviewerAssignmentRepository(db),
+		stats:       postgres.NewStatisticsRepository(db),
+		events:      postgres.NewPREventRepository(db),
+		webhooks:    postgres.NewWebhookSubscriptionRepository(db),
+		deliveries:  postgres.NewWebhookDeliveryRepository(db),
+		identities:  postgres.NewIdentityRepository(db),
+		inbound:     postgres.NewInboundDeliveryRepository(db),
+		txManager:   postgres.NewTxManager(db),
+	}
+}
+
+func newMemoryRepositories() *repositories {
+	store := memory.NewStore()
+	return &repositories{
+		users:       memory.NewUserRepository(store),
+		teams:       memory.NewTeamRepository(store),
+		prs:         memory.NewPullRequestRepository(store),
+		assignments: memory.NewReviewerAssignmentRepository(store),
+		stats:       memory.NewStatisticsRepository(store),
+		events:      memory.NewPREventRepository(store),
+		webhooks:    memory.NewWebhookSubscriptionRepository(store),
+		deliveries:  memory.NewWebhookDeliveryRepository(store),
+		identities:  memory.NewIdentityRepository(store),
+		inbound:     memory.NewInboundDeliveryRepository(store),
+		txManager:   memory.NewTxManager(store),
+	}
+}
+
+// openDatabase открывает пул соединений с настройками из конфигурации и
+// проверяет, что база доступна.
+func openDatabase(cfg config.DatabaseConfig) (*sql.DB, error) {
+	db, err := sql.Open("postgres", cfg.DSN())
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
@@ -0,0 +1,199 @@
+// Package auth проверяет учётные данные запросов: статические API-токены
+// и JWT, подписанные HS256 или RS256.
+package auth
+
+import (
+	"crypto/rsa"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type Role string
+
+const (
+	RoleAdmin    Role = "admin"
+	RoleTeamLead Role = "team-lead"
+	RoleMember   Role = "member"
+	RoleBot      Role = "bot"
+)
+
+func (r Role) IsValid() bool {
+	switch r {
+	case RoleAdmin, RoleTeamLead, RoleMember, RoleBot:
+		return true
+	}
+	return false
+}
+
+// Principal — кто выполняет запрос. Subject попадает в журнал изменений PR
+// как инициатор; Team — имя команды, которой управляет team-lead или чьи PR
+// обслуживает бот.
+type Principal struct {
+	Subject string
+	Role    Role
+	Team    string
+}
+
+// CanManageTeam — может ли вызывающий менять состав и настройки команды.
+func (p *Principal) CanManageTeam(teamName string) bool {
+	switch p.Role {
+	case RoleAdmin:
+		return true
+	case RoleTeamLead:
+		return p.Team != "" && p.Team == teamName
+	}
+	return false
+}
+
+// CanActAs — может ли вызывающий действовать от имени пользователя userID,
+// например оставить ревью за назначенного ревьюера. Остальные роли
+// действуют только от своего имени.
+func (p *Principal) CanActAs(userID string) bool {
+	switch p.Role {
+	case RoleAdmin, RoleBot:
+		return true
+	}
+	return p.Subject == userID
+}
+
+var ErrUnauthenticated = errors.New("invalid or missing credentials")
+
+type Config struct {
+	// StaticTokens — токен -> владелец
+	StaticTokens map[string]Principal
+	// HS256Secret включает JWT с общим секретом
+	HS256Secret []byte
+	// RS256PublicKey включает JWT, подписанные закрытым ключом издателя
+	RS256PublicKey *rsa.PublicKey
+	// Issuer и Audience, если заданы, сверяются с iss и aud токена
+	Issuer   string
+	Audience string
+}
+
+type Authenticator struct {
+	staticTokens map[string]Principal
+	hsSecret     []byte
+	rsKey        *rsa.PublicKey
+	parser       *jwt.Parser
+}
+
+func NewAuthenticator(config Config) (*Authenticator, error) {
+	if len(config.StaticTokens) == 0 && len(config.HS256Secret) == 0 && config.RS256PublicKey == nil {
+		return nil, errors.New("no API tokens or JWT keys configured")
+	}
+	for _, principal := range config.StaticTokens {
+		if !principal.Role.IsValid() {
+			return nil, fmt.Errorf("static token for %q has unknown role %q", principal.Subject, principal.Role)
+		}
+	}
+
+	methods := make([]string, 0, 2)
+	if len(config.HS256Secret) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if config.RS256PublicKey != nil {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	options := []jwt.ParserOption{jwt.WithValidMethods(methods), jwt.WithExpirationRequired()}
+	if config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(config.Issuer))
+	}
+	if config.Audience != "" {
+		options = append(options, jwt.WithAudience(config.Audience))
+	}
+
+	return &Authenticator{
+		staticTokens: config.StaticTokens,
+		hsSecret:     config.HS256Secret,
+		rsKey:        config.RS256PublicKey,
+		parser:       jwt.NewParser(options...),
+	}, nil
+}
+
+type claims struct {
+	jwt.RegisteredClaims
+	Role Role   `json:"role"`
+	Team string `json:"team,omitempty"`
+}
+
+// Authenticate возвращает владельца токена: сначала ищется статический
+// токен, затем токен разбирается как JWT.
+func (a *Authenticator) Authenticate(token string) (*Principal, error) {
+	if token == "" {
+		return nil, ErrUnauthenticated
+	}
+	if principal, ok := a.staticPrincipal(token); ok {
+		return principal, nil
+	}
+	if len(a.hsSecret) == 0 && a.rsKey == nil {
+		return nil, ErrUnauthenticated
+	}
+
+	var c claims
+	if _, err := a.parser.ParseWithClaims(token, &c, a.key); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	if c.Subject == "" || !c.Role.IsValid() {
+		return nil, fmt.Errorf("%w: token has no subject or unknown role", ErrUnauthenticated)
+	}
+	return &Principal{Subject: c.Subject, Role: c.Role, Team: c.Team}, nil
+}
+
+// staticPrincipal сравнивает токен со всеми статическими за постоянное
+// время, чтобы по задержке ответа нельзя было подобрать токен.
+func (a *Authenticator) staticPrincipal(token string) (*Principal, bool) {
+	var found *Principal
+	for candidate, principal := range a.staticTokens {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(token)) == 1 {
+			p := principal
+			found = &p
+		}
+	}
+	return found, found != nil
+}
+
+// key выбирает ключ проверки по алгоритму токена; допустимость алгоритма
+// уже проверена парсером.
+func (a *Authenticator) key(token *jwt.Token) (any, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return a.hsSecret, nil
+	case *jwt.SigningMethodRSA:
+		return a.rsKey, nil
+	}
+	return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+}
+
+// ParseStaticTokens разбирает список токенов вида
+// "<token>:<role>:<subject>[:<team>]" через запятую.
+func ParseStaticTokens(spec string) (map[string]Principal, error) {
+	tokens := make(map[string]Principal)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 4)
+		if len(parts) < 3 || parts[0] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("invalid token entry %q: want <token>:<role>:<subject>[:<team>]", entry)
+		}
+		principal := Principal{Role: Role(parts[1]), Subject: parts[2]}
+		if len(parts) == 4 {
+			principal.Team = parts[3]
+		}
+		if !principal.Role.IsValid() {
+			return nil, fmt.Errorf("invalid token entry for %q: unknown role %q", principal.Subject, principal.Role)
+		}
+		tokens[parts[0]] = principal
+	}
+	return tokens, nil
+}
+
+// ParseRSAPublicKey разбирает открытый ключ в PEM.
+func ParseRSAPublicKey(pem []byte) (*rsa.PublicKey, error) {
+	return jwt.ParseRSAPublicKeyFromPEM(pem)
+}
@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func signToken(t *testing.T, method jwt.SigningMethod, key any, c claims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(method, c).SignedString(key)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return token
+}
+
+func validClaims(role Role, team string) claims {
+	return claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "u1",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		Role: role,
+		Team: team,
+	}
+}
+
+func TestStaticTokens(t *testing.T) {
+	tokens, err := ParseStaticTokens("t-admin:admin:ops, t-lead:team-lead:u7:backend")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	authenticator, err := NewAuthenticator(Config{StaticTokens: tokens})
+	if err != nil {
+		t.Fatalf("new authenticator: %v", err)
+	}
+
+	principal, err := authenticator.Authenticate("t-lead")
+	if err != nil {
+		t.Fatalf("authenticate: %v", err)
+	}
+	if *principal != (Principal{Subject: "u7", Role: RoleTeamLead, Team: "backend"}) {
+		t.Errorf("unexpected principal: %+v", principal)
+	}
+	if !principal.CanManageTeam("backend") || principal.CanManageTeam("frontend") {
+		t.Errorf("team lead must manage only own team")
+	}
+	if !principal.CanActAs("u7") || principal.CanActAs("u8") {
+		t.Errorf("team lead must act only on own behalf")
+	}
+
+	if _, err := authenticator.Authenticate("t-unknown"); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("expected ErrUnauthenticated, got %v", err)
+	}
+
+	if _, err := ParseStaticTokens("t:root:ops"); err == nil {
+		t.Errorf("expected error for unknown role")
+	}
+}
+
+func TestHS256(t *testing.T) {
+	secret := []byte("hs-secret")
+	authenticator, err := NewAuthenticator(Config{HS256Secret: secret})
+	if err != nil {
+		t.Fatalf("new authenticator: %v", err)
+	}
+
+	principal, err := authenticator.Authenticate(signToken(t, jwt.SigningMethodHS256, secret, validClaims(RoleMember, "")))
+	if err != nil {
+		t.Fatalf("authenticate: %v", err)
+	}
+	if principal.Subject != "u1" || principal.Role != RoleMember {
+		t.Errorf("unexpected principal: %+v", principal)
+	}
+
+	expired := validClaims(RoleMember, "")
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	noExpiry := validClaims(RoleMember, "")
+	noExpiry.ExpiresAt = nil
+	rejected := map[string]string{
+		"wrong secret": signToken(t, jwt.SigningMethodHS256, []byte("other"), validClaims(RoleMember, "")),
+		"expired":      signToken(t, jwt.SigningMethodHS256, secret, expired),
+		"no expiry":    signToken(t, jwt.SigningMethodHS256, secret, noExpiry),
+		"unknown role": signToken(t, jwt.SigningMethodHS256, secret, validClaims("root", "")),
+	}
+	for name, token := range rejected {
+		if _, err := authenticator.Authenticate(token); !errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("%s: expected ErrUnauthenticated, got %v", name, err)
+		}
+	}
+}
+
+func TestRS256(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	publicKey, err := ParseRSAPublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	if err != nil {
+		t.Fatalf("parse key: %v", err)
+	}
+
+	authenticator, err := NewAuthenticator(Config{RS256PublicKey: publicKey})
+	if err != nil {
+		t.Fatalf("new authenticator: %v", err)
+	}
+
+	principal, err := authenticator.Authenticate(signToken(t, jwt.SigningMethodRS256, privateKey, validClaims(RoleTeamLead, "backend")))
+	if err != nil {
+		t.Fatalf("authenticate: %v", err)
+	}
+	if principal.Role != RoleTeamLead || principal.Team != "backend" {
+		t.Errorf("unexpected principal: %+v", principal)
+	}
+
+	// HS256, подписанный открытым ключом, не должен приниматься, когда
+	// настроен только RS256
+	forged := signToken(t, jwt.SigningMethodHS256, der, validClaims(RoleAdmin, ""))
+	if _, err := authenticator.Authenticate(forged); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("expected ErrUnauthenticated for HS256 token, got %v", err)
+	}
+}
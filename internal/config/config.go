@@ -0,0 +1,486 @@
+// Package config собирает настройки сервиса из YAML-файла, переменных
+// окружения и флагов командной строки. Приоритет по возрастанию: значения
+// по умолчанию, файл, окружение, флаги.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/danonenka/PR-service/internal/domain"
+	"github.com/danonenka/PR-service/internal/logging"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redacted подставляется вместо секретов в Dump.
+const redacted = "***"
+
+// Хранилища данных сервиса.
+const (
+	StoragePostgres = "postgres"
+	// StorageMemory держит данные в памяти процесса — для локального
+	// запуска без базы; при остановке всё теряется
+	StorageMemory = "memory"
+)
+
+type Config struct {
+	Storage      string             `yaml:"storage"`
+	Server       ServerConfig       `yaml:"server"`
+	Database     DatabaseConfig     `yaml:"database"`
+	Log          LogConfig          `yaml:"log"`
+	Auth         AuthConfig         `yaml:"auth"`
+	GitHub       GitHubConfig       `yaml:"github"`
+	Webhooks     WebhookConfig      `yaml:"webhooks"`
+	Reviewers    ReviewerConfig     `yaml:"reviewers"`
+	Reassignment ReassignmentConfig `yaml:"reassignment"`
+
+	// File — путь к прочитанному YAML-файлу, пустой без -config
+	File string `yaml:"-"`
+}
+
+type ServerConfig struct {
+	Addr              string        `yaml:"addr"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+	// ShutdownTimeout — сколько ждать текущие запросы после SIGTERM
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	OpenAPIPath     string        `yaml:"openapi_path"`
+}
+
+type DatabaseConfig struct {
+	// URL (DATABASE_URL) имеет приоритет над отдельными полями подключения
+	URL      string `yaml:"url"`
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+	SSLMode  string `yaml:"sslmode"`
+
+	// 0 в MaxOpenConns — без ограничения; MaxIdleConns больше MaxOpenConns
+	// database/sql сам урезает до MaxOpenConns
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+
+	MigrateOnStart bool `yaml:"migrate_on_start"`
+}
+
+type LogConfig struct {
+	Level string `yaml:"level"`
+}
+
+type AuthConfig struct {
+	// Disabled отключает аутентификацию — только для локальной отладки
+	Disabled bool `yaml:"disabled"`
+	// APITokens — список <token>:<role>:<subject>[:<team>] через запятую
+	APITokens             string `yaml:"api_tokens"`
+	JWTHS256Secret        string `yaml:"jwt_hs256_secret"`
+	JWTRS256PublicKeyFile string `yaml:"jwt_rs256_public_key_file"`
+	JWTIssuer             string `yaml:"jwt_issuer"`
+	JWTAudience           string `yaml:"jwt_audience"`
+}
+
+type GitHubConfig struct {
+	WebhookSecret string `yaml:"webhook_secret"`
+}
+
+type WebhookConfig struct {
+	PollInterval time.Duration `yaml:"poll_interval"`
+	BatchSize    int           `yaml:"batch_size"`
+	MaxAttempts  int           `yaml:"max_attempts"`
+	BaseBackoff  time.Duration `yaml:"base_backoff"`
+	MaxBackoff   time.Duration `yaml:"max_backoff"`
+	Timeout      time.Duration `yaml:"timeout"`
+}
+
+// ReviewerConfig — настройки ревью для команд, создаваемых через /team/add.
+type ReviewerConfig struct {
+	Strategy     domain.ReviewerStrategy `yaml:"strategy"`
+	MinReviewers int                     `yaml:"min_reviewers"`
+	MaxReviewers int                     `yaml:"max_reviewers"`
+}
+
+type ReassignmentConfig struct {
+	Workers  int           `yaml:"workers"`
+	Deadline time.Duration `yaml:"deadline"`
+}
+
+func Default() *Config {
+	return &Config{
+		Storage: StoragePostgres,
+		Server: ServerConfig{
+			Addr:              "0.0.0.0:8080",
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       10 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       60 * time.Second,
+			ShutdownTimeout:   20 * time.Second,
+			OpenAPIPath:       "./openapi.yaml",
+		},
+		Database: DatabaseConfig{
+			Host:            "localhost",
+			Port:            5432,
+			User:            "postgres",
+			Password:        "postgres",
+			Name:            "pr_service",
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    25,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+		},
+		Log: LogConfig{Level: "info"},
+		Webhooks: WebhookConfig{
+			PollInterval: 2 * time.Second,
+			BatchSize:    50,
+			MaxAttempts:  8,
+			BaseBackoff:  5 * time.Second,
+			MaxBackoff:   time.Hour,
+			Timeout:      10 * time.Second,
+		},
+		Reviewers: ReviewerConfig{
+			Strategy:     domain.ReviewerStrategyRandom,
+			MinReviewers: domain.DefaultMinReviewers,
+			MaxReviewers: domain.DefaultMaxReviewers,
+		},
+		Reassignment: ReassignmentConfig{
+			Workers:  8,
+			Deadline: 10 * time.Second,
+		},
+	}
+}
+
+// Load разбирает флаги из args и возвращает проверенную конфигурацию и
+// оставшиеся аргументы (подкоманду). Путь к файлу задаётся -config или
+// CONFIG_FILE; lookupEnv обычно os.LookupEnv.
+func Load(args []string, lookupEnv func(string) (string, bool)) (*Config, []string, error) {
+	cfg := Default()
+	settings := cfg.settings()
+
+	flags := flag.NewFlagSet("pr-service", flag.ContinueOnError)
+	configFile := flags.String("config", "", "path to YAML config file (env CONFIG_FILE)")
+	// Флаги применяются после файла и окружения, поэтому пока только запоминаются
+	type flagValue struct {
+		setting setting
+		value   string
+	}
+	var passed []flagValue
+	for _, s := range settings {
+		if s.secret {
+			// Секреты во флагах видны в списке процессов, их задают файлом или окружением
+			continue
+		}
+		s := s
+		flags.Func(s.flagName(), s.env, func(value string) error {
+			passed = append(passed, flagValue{setting: s, value: value})
+			return nil
+		})
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if *configFile == "" {
+		*configFile, _ = lookupEnv("CONFIG_FILE")
+	}
+	if *configFile != "" {
+		if err := cfg.loadFile(*configFile); err != nil {
+			return nil, nil, err
+		}
+		cfg.File = *configFile
+	}
+
+	explicit := make(map[string]bool)
+	for _, s := range settings {
+		if value, ok := lookupEnv(s.env); ok && value != "" {
+			explicit[s.env] = true
+		}
+	}
+	for _, f := range passed {
+		explicit[f.setting.env] = true
+	}
+
+	for _, s := range settings {
+		if explicit[s.fallbackFor] {
+			continue
+		}
+		if value, ok := lookupEnv(s.env); ok && value != "" {
+			if err := s.set(value); err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", s.env, err)
+			}
+		}
+	}
+	for _, f := range passed {
+		if explicit[f.setting.fallbackFor] {
+			continue
+		}
+		if err := f.setting.set(f.value); err != nil {
+			return nil, nil, fmt.Errorf("-%s: %w", f.setting.flagName(), err)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return cfg, flags.Args(), nil
+}
+
+func (c *Config) loadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate собирает все ошибки сразу, чтобы их не приходилось исправлять
+// по одной на каждый запуск.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	if _, port, err := net.SplitHostPort(c.Server.Addr); err != nil || port == "" {
+		errs = append(errs, fmt.Errorf("server.addr: %q is not host:port", c.Server.Addr))
+	}
+	for _, d := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"server.read_header_timeout", c.Server.ReadHeaderTimeout},
+		{"server.read_timeout", c.Server.ReadTimeout},
+		{"server.write_timeout", c.Server.WriteTimeout},
+		{"server.idle_timeout", c.Server.IdleTimeout},
+		{"server.shutdown_timeout", c.Server.ShutdownTimeout},
+		{"webhooks.poll_interval", c.Webhooks.PollInterval},
+		{"webhooks.base_backoff", c.Webhooks.BaseBackoff},
+		{"webhooks.max_backoff", c.Webhooks.MaxBackoff},
+		{"webhooks.timeout", c.Webhooks.Timeout},
+		{"reassignment.deadline", c.Reassignment.Deadline},
+	} {
+		check(d.value > 0, "%s must be positive", d.name)
+	}
+
+	check(c.Storage == StoragePostgres || c.Storage == StorageMemory,
+		"storage: %q is not %s or %s", c.Storage, StoragePostgres, StorageMemory)
+	switch {
+	case c.Storage == StorageMemory:
+		// Параметры подключения к базе не используются
+	case c.Database.URL != "":
+		u, err := url.Parse(c.Database.URL)
+		check(err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql"),
+			"database.url must be a postgres:// URL")
+	default:
+		check(c.Database.Host != "", "database.host is required")
+		check(c.Database.Port > 0 && c.Database.Port <= 65535, "database.port must be in 1..65535")
+		check(c.Database.Name != "", "database.name is required")
+	}
+	check(c.Database.MaxOpenConns >= 0, "database.max_open_conns must not be negative")
+	check(c.Database.MaxIdleConns >= 0, "database.max_idle_conns must not be negative")
+	check(c.Database.ConnMaxLifetime >= 0, "database.conn_max_lifetime must not be negative")
+	check(c.Database.ConnMaxIdleTime >= 0, "database.conn_max_idle_time must not be negative")
+
+	if _, err := logging.ParseLevel(c.Log.Level); err != nil {
+		errs = append(errs, fmt.Errorf("log.level: %w", err))
+	}
+
+	check(c.Webhooks.BatchSize > 0, "webhooks.batch_size must be positive")
+	check(c.Webhooks.MaxAttempts > 0, "webhooks.max_attempts must be positive")
+	check(c.Webhooks.MaxBackoff >= c.Webhooks.BaseBackoff, "webhooks.max_backoff must not be less than base_backoff")
+
+	check(c.Reviewers.Strategy.IsValid(), "reviewers.strategy: unknown strategy %q", c.Reviewers.Strategy)
+	check(domain.ValidReviewerLimits(c.Reviewers.MinReviewers, c.Reviewers.MaxReviewers),
+		"reviewers: need 0 <= min_reviewers <= max_reviewers <= %d", domain.MaxReviewersLimit)
+	check(c.Reassignment.Workers > 0, "reassignment.workers must be positive")
+
+	return errors.Join(errs...)
+}
+
+// DSN — строка подключения для lib/pq.
+func (c DatabaseConfig) DSN() string {
+	if c.URL != "" {
+		return c.URL
+	}
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		c.Host, c.Port, c.User, c.Password, c.Name, c.SSLMode)
+}
+
+// Redacted возвращает копию, в которой секреты заменены на *** (пароль в
+// DATABASE_URL — на xxxxx, как в url.URL.Redacted); пустые секреты
+// остаются пустыми, чтобы было видно, что они не заданы.
+func (c *Config) Redacted() *Config {
+	copied := *c
+	mask := func(value *string) {
+		if *value != "" {
+			*value = redacted
+		}
+	}
+	mask(&copied.Database.Password)
+	mask(&copied.Auth.APITokens)
+	mask(&copied.Auth.JWTHS256Secret)
+	mask(&copied.GitHub.WebhookSecret)
+	if copied.Database.URL != "" {
+		if u, err := url.Parse(copied.Database.URL); err == nil {
+			copied.Database.URL = u.Redacted()
+		} else {
+			copied.Database.URL = redacted
+		}
+	}
+	return &copied
+}
+
+// Dump пишет действующую конфигурацию в YAML без секретов.
+func (c *Config) Dump(w io.Writer) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c.Redacted()); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// setting связывает поле конфигурации с переменной окружения; имя флага
+// выводится из имени переменной: DB_MAX_OPEN_CONNS -> -db-max-open-conns.
+type setting struct {
+	env    string
+	secret bool
+	// fallbackFor — более новая настройка с тем же смыслом; если она задана
+	// окружением или флагом, эта настройка игнорируется
+	fallbackFor string
+	set         func(value string) error
+}
+
+func (s setting) flagName() string {
+	name := []byte(s.env)
+	for i, ch := range name {
+		switch {
+		case ch == '_':
+			name[i] = '-'
+		case ch >= 'A' && ch <= 'Z':
+			name[i] = ch + 'a' - 'A'
+		}
+	}
+	return string(name)
+}
+
+func (c *Config) settings() []setting {
+	return []setting{
+		{env: "STORAGE", set: setString(&c.Storage)},
+
+		{env: "HTTP_ADDR", set: setString(&c.Server.Addr)},
+		// PORT оставлен для совместимости: слушать на всех интерфейсах
+		{env: "PORT", fallbackFor: "HTTP_ADDR", set: func(value string) error {
+			c.Server.Addr = net.JoinHostPort("0.0.0.0", value)
+			return nil
+		}},
+		{env: "HTTP_READ_HEADER_TIMEOUT", set: setDuration(&c.Server.ReadHeaderTimeout)},
+		{env: "HTTP_READ_TIMEOUT", set: setDuration(&c.Server.ReadTimeout)},
+		{env: "HTTP_WRITE_TIMEOUT", set: setDuration(&c.Server.WriteTimeout)},
+		{env: "HTTP_IDLE_TIMEOUT", set: setDuration(&c.Server.IdleTimeout)},
+		{env: "SHUTDOWN_TIMEOUT", set: setDuration(&c.Server.ShutdownTimeout)},
+		{env: "OPENAPI_PATH", set: setString(&c.Server.OpenAPIPath)},
+
+		{env: "DATABASE_URL", secret: true, set: setString(&c.Database.URL)},
+		{env: "DB_HOST", set: setString(&c.Database.Host)},
+		{env: "DB_PORT", set: setInt(&c.Database.Port)},
+		{env: "DB_USER", set: setString(&c.Database.User)},
+		{env: "DB_PASSWORD", secret: true, set: setString(&c.Database.Password)},
+		{env: "DB_NAME", set: setString(&c.Database.Name)},
+		{env: "DB_SSLMODE", set: setString(&c.Database.SSLMode)},
+		{env: "DB_MAX_OPEN_CONNS", set: setInt(&c.Database.MaxOpenConns)},
+		{env: "DB_MAX_IDLE_CONNS", set: setInt(&c.Database.MaxIdleConns)},
+		{env: "DB_CONN_MAX_LIFETIME", set: setDuration(&c.Database.ConnMaxLifetime)},
+		{env: "DB_CONN_MAX_IDLE_TIME", set: setDuration(&c.Database.ConnMaxIdleTime)},
+		{env: "MIGRATE_ON_START", set: setBool(&c.Database.MigrateOnStart)},
+
+		{env: "LOG_LEVEL", set: setString(&c.Log.Level)},
+
+		{env: "AUTH_DISABLED", set: setBool(&c.Auth.Disabled)},
+		{env: "API_TOKENS", secret: true, set: setString(&c.Auth.APITokens)},
+		{env: "JWT_HS256_SECRET", secret: true, set: setString(&c.Auth.JWTHS256Secret)},
+		{env: "JWT_RS256_PUBLIC_KEY_FILE", set: setString(&c.Auth.JWTRS256PublicKeyFile)},
+		{env: "JWT_ISSUER", set: setString(&c.Auth.JWTIssuer)},
+		{env: "JWT_AUDIENCE", set: setString(&c.Auth.JWTAudience)},
+
+		{env: "GITHUB_WEBHOOK_SECRET", secret: true, set: setString(&c.GitHub.WebhookSecret)},
+
+		{env: "WEBHOOK_POLL_INTERVAL", set: setDuration(&c.Webhooks.PollInterval)},
+		{env: "WEBHOOK_BATCH_SIZE", set: setInt(&c.Webhooks.BatchSize)},
+		{env: "WEBHOOK_MAX_ATTEMPTS", set: setInt(&c.Webhooks.MaxAttempts)},
+		{env: "WEBHOOK_BASE_BACKOFF", set: setDuration(&c.Webhooks.BaseBackoff)},
+		{env: "WEBHOOK_MAX_BACKOFF", set: setDuration(&c.Webhooks.MaxBackoff)},
+		{env: "WEBHOOK_TIMEOUT", set: setDuration(&c.Webhooks.Timeout)},
+
+		{env: "REVIEWER_STRATEGY", set: func(value string) error {
+			c.Reviewers.Strategy = domain.ReviewerStrategy(value)
+			return nil
+		}},
+		{env: "MIN_REVIEWERS", set: setInt(&c.Reviewers.MinReviewers)},
+		{env: "MAX_REVIEWERS", set: setInt(&c.Reviewers.MaxReviewers)},
+
+		{env: "REASSIGN_WORKERS", set: setInt(&c.Reassignment.Workers)},
+		{env: "REASSIGN_DEADLINE", set: setDuration(&c.Reassignment.Deadline)},
+	}
+}
+
+func setString(target *string) func(string) error {
+	return func(value string) error {
+		*target = value
+		return nil
+	}
+}
+
+func setInt(target *int) func(string) error {
+	return func(value string) error {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+		*target = parsed
+		return nil
+	}
+}
+
+func setBool(target *bool) func(string) error {
+	return func(value string) error {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", value)
+		}
+		*target = parsed
+		return nil
+	}
+}
+
+func setDuration(target *time.Duration) func(string) error {
+	return func(value string) error {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration like 10s or 1m", value)
+		}
+		*target = parsed
+		return nil
+	}
+}
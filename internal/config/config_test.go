@@ -0,0 +1,168 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danonenka/PR-service/internal/config"
+)
+
+func env(values map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := values[key]
+		return value, ok
+	}
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeFile(t, `
+server:
+  write_timeout: 45s
+database:
+  host: db.internal
+  max_open_conns: 40
+webhooks:
+  batch_size: 10
+reviewers:
+  strategy: round-robin
+`)
+
+	cfg, args, err := config.Load(
+		[]string{"-config", path, "-db-max-open-conns", "60", "migrate", "up"},
+		env(map[string]string{"DB_MAX_OPEN_CONNS": "50", "WEBHOOK_BATCH_SIZE": "20", "PORT": "9090"}),
+	)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	if cfg.Server.WriteTimeout != 45*time.Second {
+		t.Errorf("file must override default, got write_timeout %s", cfg.Server.WriteTimeout)
+	}
+	if cfg.Server.ReadTimeout != 10*time.Second {
+		t.Errorf("default read_timeout expected, got %s", cfg.Server.ReadTimeout)
+	}
+	if cfg.Webhooks.BatchSize != 20 {
+		t.Errorf("env must override file, got batch_size %d", cfg.Webhooks.BatchSize)
+	}
+	if cfg.Database.MaxOpenConns != 60 {
+		t.Errorf("flag must override env, got max_open_conns %d", cfg.Database.MaxOpenConns)
+	}
+	if cfg.Database.Host != "db.internal" || cfg.Reviewers.Strategy != "round-robin" {
+		t.Errorf("file values lost: %+v", cfg)
+	}
+	if cfg.Server.Addr != "0.0.0.0:9090" {
+		t.Errorf("PORT must set addr, got %s", cfg.Server.Addr)
+	}
+	if strings.Join(args, " ") != "migrate up" {
+		t.Errorf("expected subcommand args, got %v", args)
+	}
+}
+
+// PORT оставлен для совместимости и не должен перебивать явный HTTP_ADDR.
+func TestLoadPortDoesNotOverrideAddr(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+	}{
+		{name: "env", env: map[string]string{"HTTP_ADDR": "127.0.0.1:8081", "PORT": "9090"}},
+		{name: "flags", args: []string{"-http-addr", "127.0.0.1:8081", "-port", "9090"}},
+		{name: "flag and env", args: []string{"-http-addr", "127.0.0.1:8081"}, env: map[string]string{"PORT": "9090"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, _, err := config.Load(tt.args, env(tt.env))
+			if err != nil {
+				t.Fatalf("load: %v", err)
+			}
+			if cfg.Server.Addr != "127.0.0.1:8081" {
+				t.Errorf("HTTP_ADDR must win over PORT, got %s", cfg.Server.Addr)
+			}
+		})
+	}
+}
+
+func TestLoadDatabaseURL(t *testing.T) {
+	url := "postgres://app:s3cret@db:5432/pr?sslmode=require"
+	cfg, _, err := config.Load(nil, env(map[string]string{"DATABASE_URL": url, "DB_HOST": "ignored"}))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Database.DSN() != url {
+		t.Errorf("DATABASE_URL must take priority, got %s", cfg.Database.DSN())
+	}
+}
+
+func TestLoadRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		env   map[string]string
+		file  string
+		error string
+	}{
+		{name: "unknown file key", file: "server:\n  adr: x\n", error: "field adr not found"},
+		{name: "bad duration", env: map[string]string{"SHUTDOWN_TIMEOUT": "soon"}, error: "SHUTDOWN_TIMEOUT"},
+		{name: "bad url", env: map[string]string{"DATABASE_URL": "mysql://db"}, error: "database.url"},
+		{name: "reviewer limits", args: []string{"-min-reviewers", "3", "-max-reviewers", "2"}, error: "min_reviewers"},
+		{name: "strategy", env: map[string]string{"REVIEWER_STRATEGY": "alphabetical"}, error: "reviewers.strategy"},
+		{name: "secret flag", args: []string{"-db-password", "x"}, error: "flag provided but not defined"},
+		{name: "storage", env: map[string]string{"STORAGE": "redis"}, error: "storage"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tt.args
+			if tt.file != "" {
+				args = append([]string{"-config", writeFile(t, tt.file)}, args...)
+			}
+			_, _, err := config.Load(args, env(tt.env))
+			if err == nil || !strings.Contains(err.Error(), tt.error) {
+				t.Errorf("expected error containing %q, got %v", tt.error, err)
+			}
+		})
+	}
+}
+
+func TestDumpRedactsSecrets(t *testing.T) {
+	cfg, _, err := config.Load(nil, env(map[string]string{
+		"DATABASE_URL":          "postgres://app:db-pass@db/pr",
+		"API_TOKENS":            "tok-123:admin:ops",
+		"JWT_HS256_SECRET":      "jwt-secret",
+		"GITHUB_WEBHOOK_SECRET": "gh-secret",
+	}))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	var out strings.Builder
+	if err := cfg.Dump(&out); err != nil {
+		t.Fatalf("dump: %v", err)
+	}
+	dump := out.String()
+	for _, secret := range []string{"db-pass", "tok-123", "jwt-secret", "gh-secret", "password: postgres"} {
+		if strings.Contains(dump, secret) {
+			t.Errorf("dump leaks %q:\n%s", secret, dump)
+		}
+	}
+	for _, line := range []string{"url: postgres://app:xxxxx@db/pr", "write_timeout: 30s"} {
+		if !strings.Contains(dump, line) {
+			t.Errorf("dump misses %q:\n%s", line, dump)
+		}
+	}
+	if cfg.Auth.APITokens != "tok-123:admin:ops" {
+		t.Error("Dump must not modify the config")
+	}
+}
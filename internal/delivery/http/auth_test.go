@@ -0,0 +1,200 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/danonenka/PR-service/internal/auth"
+	httphandler "github.com/danonenka/PR-service/internal/delivery/http"
+	"github.com/danonenka/PR-service/internal/domain"
+	"github.com/danonenka/PR-service/internal/repository/memory"
+	"github.com/danonenka/PR-service/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Отказы выдаются до обработчиков, поэтому usecase здесь не нужны.
+func TestRouteAuthorization(t *testing.T) {
+	tokens, err := auth.ParseStaticTokens("t-member:member:u1,t-bot:bot:ci,t-lead:team-lead:u2:backend")
+	if err != nil {
+		t.Fatalf("parse tokens: %v", err)
+	}
+	authenticator, err := auth.NewAuthenticator(auth.Config{StaticTokens: tokens})
+	if err != nil {
+		t.Fatalf("new authenticator: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	httphandler.NewRouter(nil, nil, nil, nil, nil, nil, "", authenticator).SetupRoutes(engine)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		body   string
+		status int
+		code   string
+	}{
+		{name: "no token", method: http.MethodPost, path: "/users/setIsActive", status: http.StatusUnauthorized, code: "UNAUTHORIZED"},
+		{name: "unknown token", method: http.MethodGet, path: "/team/get", token: "nope", status: http.StatusUnauthorized, code: "UNAUTHORIZED"},
+		{name: "member rewrites team", method: http.MethodPost, path: "/team/add", token: "t-member", status: http.StatusForbidden, code: "FORBIDDEN"},
+		{name: "member deactivates user", method: http.MethodPost, path: "/users/setIsActive", token: "t-member", status: http.StatusForbidden, code: "FORBIDDEN"},
+		{name: "bot reviews", method: http.MethodPost, path: "/pullRequest/review", token: "t-bot", status: http.StatusForbidden, code: "FORBIDDEN"},
+		{name: "team lead manages webhooks", method: http.MethodGet, path: "/webhooks", token: "t-lead", status: http.StatusForbidden, code: "FORBIDDEN"},
+		{name: "member reviews as another reviewer", method: http.MethodPost, path: "/pullRequest/review", token: "t-member",
+			body: `{"pull_request_id":"pr1","reviewer_id":"u3","state":"APPROVED"}`, status: http.StatusForbidden, code: "FORBIDDEN"},
+		{name: "team lead edits other team", method: http.MethodPost, path: "/team/settings", token: "t-lead", status: http.StatusForbidden, code: "FORBIDDEN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := tt.body
+			if body == "" {
+				body = `{"team_name":"frontend"}`
+			}
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status || !strings.Contains(rec.Body.String(), `"code":"`+tt.code+`"`) {
+				t.Errorf("got %d %s, want %d %s", rec.Code, rec.Body.String(), tt.status, tt.code)
+			}
+		})
+	}
+}
+
+// Через /team/add team-lead не должен забирать в свою команду участников
+// чужой, иначе он мог бы их перевести или деактивировать.
+func TestTeamLeadCannotTakeOverMembers(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	userRepo := memory.NewUserRepository(store)
+	teamRepo := memory.NewTeamRepository(store)
+	teamUsecase := usecase.NewTeamUsecase(teamRepo, userRepo, memory.NewTxManager(store))
+	userUsecase := usecase.NewUserUsecase(userRepo, teamRepo, nil)
+
+	for team, member := range map[string]string{"backend": "u2", "frontend": "u9"} {
+		if _, err := teamUsecase.AddTeamWithMembers(ctx, team, "", []*domain.User{{ID: member, Name: member, IsActive: true}}); err != nil {
+			t.Fatalf("add team %s: %v", team, err)
+		}
+	}
+
+	tokens, err := auth.ParseStaticTokens("t-lead:team-lead:u2:backend")
+	if err != nil {
+		t.Fatalf("parse tokens: %v", err)
+	}
+	authenticator, err := auth.NewAuthenticator(auth.Config{StaticTokens: tokens})
+	if err != nil {
+		t.Fatalf("new authenticator: %v", err)
+	}
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	httphandler.NewRouter(userUsecase, teamUsecase, nil, nil, nil, nil, "", authenticator).SetupRoutes(engine)
+
+	body := `{"team_name":"backend","members":[{"user_id":"u2","username":"u2","is_active":true},{"user_id":"u9","username":"u9","is_active":false}]}`
+	req := httptest.NewRequest(http.MethodPost, "/team/add", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer t-lead")
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), `"code":"FORBIDDEN"`) {
+		t.Fatalf("got %d %s, want 403 FORBIDDEN", rec.Code, rec.Body.String())
+	}
+	user, err := userUsecase.GetUserByID(ctx, "u9")
+	if err != nil {
+		t.Fatalf("get user: %v", err)
+	}
+	if team, err := teamUsecase.GetTeamByID(ctx, user.TeamID); err != nil || team.Name != "frontend" || !user.IsActive {
+		t.Errorf("member of another team changed: %+v, team %v, %v", user, team, err)
+	}
+}
+
+// Менять PR можно только своей команды: команда PR — команда его автора.
+func TestPRChangesLimitedToOwnTeam(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	userRepo := memory.NewUserRepository(store)
+	teamRepo := memory.NewTeamRepository(store)
+	txManager := memory.NewTxManager(store)
+	teamUsecase := usecase.NewTeamUsecase(teamRepo, userRepo, txManager)
+	userUsecase := usecase.NewUserUsecase(userRepo, teamRepo, nil)
+	prUsecase := usecase.NewPRUsecase(memory.NewPullRequestRepository(store), userRepo, teamRepo,
+		memory.NewReviewerAssignmentRepository(store), memory.NewPREventRepository(store), txManager, usecase.NewReviewerService())
+
+	teams := map[string][]string{"backend": {"u1", "u2"}, "frontend": {"u8", "u9"}}
+	for team, members := range teams {
+		users := make([]*domain.User, 0, len(members))
+		for _, id := range members {
+			users = append(users, &domain.User{ID: id, Name: id, IsActive: true})
+		}
+		if _, err := teamUsecase.AddTeamWithMembers(ctx, team, "", users); err != nil {
+			t.Fatalf("add team %s: %v", team, err)
+		}
+	}
+	for _, pr := range []*domain.PullRequest{{ID: "pr-backend", Title: "b", AuthorID: "u1", Status: domain.PRStatusOpen}, {ID: "pr-frontend", Title: "f", AuthorID: "u8", Status: domain.PRStatusOpen}} {
+		if err := prUsecase.CreatePR(ctx, pr, ""); err != nil {
+			t.Fatalf("create %s: %v", pr.ID, err)
+		}
+	}
+
+	tokens, err := auth.ParseStaticTokens("t-member:member:u2,t-lead:team-lead:u1:backend,t-bot:bot:ci:backend,t-bot-any:bot:ci")
+	if err != nil {
+		t.Fatalf("parse tokens: %v", err)
+	}
+	authenticator, err := auth.NewAuthenticator(auth.Config{StaticTokens: tokens})
+	if err != nil {
+		t.Fatalf("new authenticator: %v", err)
+	}
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	httphandler.NewRouter(userUsecase, teamUsecase, prUsecase, nil, nil, nil, "", authenticator).SetupRoutes(engine)
+
+	tests := []struct {
+		name   string
+		path   string
+		token  string
+		body   string
+		status int
+	}{
+		{name: "member merges other team", path: "/pullRequest/merge", token: "t-member", body: `{"pull_request_id":"pr-frontend"}`, status: http.StatusForbidden},
+		{name: "member reassigns other team", path: "/pullRequest/reassign", token: "t-member",
+			body: `{"pull_request_id":"pr-frontend","old_reviewer_id":"u9"}`, status: http.StatusForbidden},
+		{name: "team lead closes other team", path: "/pullRequest/close", token: "t-lead", body: `{"pull_request_id":"pr-frontend"}`, status: http.StatusForbidden},
+		{name: "bot closes other team", path: "/pullRequest/close", token: "t-bot", body: `{"pull_request_id":"pr-frontend"}`, status: http.StatusForbidden},
+		{name: "bot without team closes", path: "/pullRequest/close", token: "t-bot-any", body: `{"pull_request_id":"pr-frontend"}`, status: http.StatusForbidden},
+		{name: "member merges own team", path: "/pullRequest/merge", token: "t-member", body: `{"pull_request_id":"pr-backend"}`, status: http.StatusOK},
+		{name: "team lead reopens missing PR", path: "/pullRequest/reopen", token: "t-lead", body: `{"pull_request_id":"pr-missing"}`, status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("got %d %s, want %d", rec.Code, rec.Body.String(), tt.status)
+			}
+		})
+	}
+
+	pr, err := prUsecase.GetPRByID(ctx, "pr-frontend")
+	if err != nil {
+		t.Fatalf("get pr: %v", err)
+	}
+	if pr.Status != domain.PRStatusOpen {
+		t.Errorf("PR of another team changed status to %s", pr.Status)
+	}
+}
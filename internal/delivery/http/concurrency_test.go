@@ -0,0 +1,274 @@
+package http_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	httphandler "github.com/danonenka/PR-service/internal/delivery/http"
+	"github.com/danonenka/PR-service/internal/repository/postgres"
+	"github.com/danonenka/PR-service/internal/testdb"
+	"github.com/danonenka/PR-service/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Тесты гоняют настоящий Postgres: TEST_DATABASE_URL должен указывать на
+// отдельную базу, её схема пересоздаётся из migrations/ на каждый тест.
+func setupServer(t *testing.T) (*gin.Engine, *sql.DB) {
+	t.Helper()
+
+	db := testdb.Open(t)
+	db.SetMaxOpenConns(20)
+	testdb.Migrate(t, db)
+
+	userRepo := postgres.NewUserRepository(db)
+	teamRepo := postgres.NewTeamRepository(db)
+	prRepo := postgres.NewPullRequestRepository(db)
+	assignmentRepo := postgres.NewReviewerAssignmentRepository(db)
+	statsRepo := postgres.NewStatisticsRepository(db)
+	eventRepo := postgres.NewPREventRepository(db)
+	webhookRepo := postgres.NewWebhookSubscriptionRepository(db)
+	deliveryRepo := postgres.NewWebhookDeliveryRepository(db)
+	identityRepo := postgres.NewIdentityRepository(db)
+	inboundRepo := postgres.NewInboundDeliveryRepository(db)
+	txManager := postgres.NewTxManager(db)
+	reviewerService := usecase.NewReviewerService()
+
+	reassignmentUsecase := usecase.NewReassignmentUsecase(prRepo, txManager, reviewerService)
+	userUsecase := usecase.NewUserUsecase(userRepo, teamRepo, reassignmentUsecase)
+	teamUsecase := usecase.NewTeamUsecase(teamRepo, userRepo, txManager)
+	prUsecase := usecase.NewPRUsecase(prRepo, userRepo, teamRepo, assignmentRepo, eventRepo, txManager, reviewerService)
+	statisticsUsecase := usecase.NewStatisticsUsecase(statsRepo, teamRepo)
+	webhookUsecase := usecase.NewWebhookUsecase(webhookRepo, deliveryRepo, teamRepo)
+	githubUsecase := usecase.NewGitHubUsecase(identityRepo, inboundRepo, userRepo, prUsecase)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	httphandler.NewRouter(userUsecase, teamUsecase, prUsecase, statisticsUsecase, webhookUsecase, githubUsecase, testGitHubSecret, nil).SetupRoutes(engine)
+
+	return engine, db
+}
+
+type apiResponse struct {
+	Status int
+	Body   map[string]any
+}
+
+func (r apiResponse) errorCode() string {
+	errBody, _ := r.Body["error"].(map[string]any)
+	code, _ := errBody["code"].(string)
+	return code
+}
+
+func doJSON(t *testing.T, engine *gin.Engine, method, path string, body any) apiResponse {
+	t.Helper()
+
+	// Вызывается из горутин, поэтому только Errorf, без FailNow
+	payload, err := json.Marshal(body)
+	if err != nil {
+		t.Errorf("marshal: %v", err)
+		return apiResponse{}
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+
+	resp := apiResponse{Status: rec.Code}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp.Body); err != nil {
+		t.Errorf("%s %s: decode %q: %v", method, path, rec.Body.String(), err)
+	}
+	return resp
+}
+
+func createTeamAndPR(t *testing.T, engine *gin.Engine, prID string, teamSize int) []string {
+	t.Helper()
+
+	members := make([]map[string]any, 0, teamSize)
+	for i := 1; i <= teamSize; i++ {
+		members = append(members, map[string]any{
+			"user_id":   fmt.Sprintf("u%d", i),
+			"username":  fmt.Sprintf("User %d", i),
+			"is_active": true,
+		})
+	}
+	if resp := doJSON(t, engine, http.MethodPost, "/team/add", map[string]any{
+		"team_name": "backend",
+		"members":   members,
+	}); resp.Status != http.StatusCreated {
+		t.Fatalf("create team: %d %v", resp.Status, resp.Body)
+	}
+
+	resp := doJSON(t, engine, http.MethodPost, "/pullRequest/create", map[string]any{
+		"pull_request_id":   prID,
+		"pull_request_name": "Add search",
+		"author_id":         "u1",
+	})
+	if resp.Status != http.StatusCreated {
+		t.Fatalf("create PR: %d %v", resp.Status, resp.Body)
+	}
+
+	pr := resp.Body["pr"].(map[string]any)
+	reviewers := make([]string, 0)
+	for _, id := range pr["assigned_reviewers"].([]any) {
+		reviewers = append(reviewers, id.(string))
+	}
+	if len(reviewers) != 2 {
+		t.Fatalf("expected 2 reviewers, got %v", reviewers)
+	}
+	return reviewers
+}
+
+func assignedReviewers(t *testing.T, db *sql.DB, prID string) []string {
+	t.Helper()
+
+	rows, err := db.Query(`SELECT reviewer_id FROM reviewer_assignments WHERE pr_id = $1 ORDER BY reviewer_id`, prID)
+	if err != nil {
+		t.Fatalf("query assignments: %v", err)
+	}
+	defer rows.Close()
+
+	reviewers := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		reviewers = append(reviewers, id)
+	}
+	return reviewers
+}
+
+func TestParallelReassignOfSameReviewer(t *testing.T) {
+	engine, db := setupServer(t)
+	reviewers := createTeamAndPR(t, engine, "pr-1", 8)
+	oldReviewer := reviewers[0]
+
+	const workers = 16
+	responses := make([]apiResponse, workers)
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			responses[i] = doJSON(t, engine, http.MethodPost, "/pullRequest/reassign", map[string]any{
+				"pull_request_id": "pr-1",
+				"old_reviewer_id": oldReviewer,
+			})
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	succeeded := 0
+	for _, resp := range responses {
+		switch {
+		case resp.Status == http.StatusOK:
+			succeeded++
+		case resp.Status == http.StatusConflict && (resp.errorCode() == "NOT_ASSIGNED" || resp.errorCode() == "CONCURRENT_MODIFICATION"):
+		default:
+			t.Errorf("unexpected response: %d %v", resp.Status, resp.Body)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("expected exactly one successful reassign, got %d", succeeded)
+	}
+
+	final := assignedReviewers(t, db, "pr-1")
+	if len(final) != 2 {
+		t.Fatalf("expected 2 reviewers after reassign, got %v", final)
+	}
+	for _, id := range final {
+		if id == oldReviewer || id == "u1" {
+			t.Errorf("unexpected reviewer %s in %v", id, final)
+		}
+	}
+}
+
+func TestParallelMergeAndReassign(t *testing.T) {
+	engine, db := setupServer(t)
+	reviewers := createTeamAndPR(t, engine, "pr-2", 8)
+
+	const reassignsPerReviewer = 8
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	responses := make([]apiResponse, 0)
+	start := make(chan struct{})
+
+	record := func(resp apiResponse) {
+		mu.Lock()
+		responses = append(responses, resp)
+		mu.Unlock()
+	}
+
+	for _, reviewer := range reviewers {
+		for i := 0; i < reassignsPerReviewer; i++ {
+			wg.Add(1)
+			go func(reviewer string) {
+				defer wg.Done()
+				<-start
+				record(doJSON(t, engine, http.MethodPost, "/pullRequest/reassign", map[string]any{
+					"pull_request_id": "pr-2",
+					"old_reviewer_id": reviewer,
+				}))
+			}(reviewer)
+		}
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-start
+		resp := doJSON(t, engine, http.MethodPost, "/pullRequest/merge", map[string]any{
+			"pull_request_id": "pr-2",
+		})
+		if resp.Status != http.StatusOK {
+			t.Errorf("merge failed: %d %v", resp.Status, resp.Body)
+		}
+	}()
+	close(start)
+	wg.Wait()
+
+	allowed := map[string]bool{
+		"PR_MERGED":               true,
+		"NOT_ASSIGNED":            true,
+		"NO_CANDIDATE":            true,
+		"CONCURRENT_MODIFICATION": true,
+	}
+	for _, resp := range responses {
+		if resp.Status == http.StatusOK {
+			continue
+		}
+		if resp.Status != http.StatusConflict || !allowed[resp.errorCode()] {
+			t.Errorf("unexpected response: %d %v", resp.Status, resp.Body)
+		}
+	}
+
+	var status string
+	if err := db.QueryRow(`SELECT status FROM pull_requests WHERE id = 'pr-2'`).Scan(&status); err != nil {
+		t.Fatalf("query status: %v", err)
+	}
+	if status != "MERGED" {
+		t.Errorf("expected MERGED, got %s", status)
+	}
+
+	final := assignedReviewers(t, db, "pr-2")
+	if len(final) != 2 {
+		t.Errorf("expected 2 reviewers after merge, got %v", final)
+	}
+
+	resp := doJSON(t, engine, http.MethodPost, "/pullRequest/reassign", map[string]any{
+		"pull_request_id": "pr-2",
+		"old_reviewer_id": final[0],
+	})
+	if resp.Status != http.StatusConflict || resp.errorCode() != "PR_MERGED" {
+		t.Errorf("expected PR_MERGED after merge, got %d %v", resp.Status, resp.Body)
+	}
+}
@@ -0,0 +1,121 @@
+package http_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danonenka/PR-service/internal/webhook"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testGitHubSecret = "github-secret"
+
+func sendGitHubEvent(t *testing.T, engine *gin.Engine, deliveryID, signature string, payload map[string]any) apiResponse {
+	t.Helper()
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if signature == "" {
+		signature = webhook.Sign(testGitHubSecret, body)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/integrations/github/webhook", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Event", "pull_request")
+	req.Header.Set("X-GitHub-Delivery", deliveryID)
+	req.Header.Set("X-Hub-Signature-256", signature)
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+
+	resp := apiResponse{Status: rec.Code}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp.Body); err != nil {
+		t.Fatalf("decode %q: %v", rec.Body.String(), err)
+	}
+	return resp
+}
+
+func pullRequestEvent(action string, merged bool) map[string]any {
+	return map[string]any{
+		"action": action,
+		"number": 42,
+		"pull_request": map[string]any{
+			"title":  "Add search",
+			"merged": merged,
+			"user":   map[string]any{"login": "Octocat"},
+		},
+		"repository": map[string]any{"full_name": "acme/api"},
+		"sender":     map[string]any{"login": "octocat"},
+	}
+}
+
+func TestGitHubWebhookLifecycle(t *testing.T) {
+	engine, _ := setupServer(t)
+	createTeamAndPR(t, engine, "pr-1", 4)
+
+	if resp := doJSON(t, engine, http.MethodPost, "/integrations/github/identities", map[string]any{
+		"login":   "octocat",
+		"user_id": "u2",
+	}); resp.Status != http.StatusOK {
+		t.Fatalf("map identity: %d %v", resp.Status, resp.Body)
+	}
+
+	resp := sendGitHubEvent(t, engine, "d-1", "sha256=deadbeef", pullRequestEvent("opened", false))
+	if resp.Status != http.StatusUnauthorized {
+		t.Fatalf("bad signature: expected 401, got %d %v", resp.Status, resp.Body)
+	}
+
+	resp = sendGitHubEvent(t, engine, "d-1", "", pullRequestEvent("opened", false))
+	if resp.Status != http.StatusOK || resp.Body["status"] != "processed" {
+		t.Fatalf("opened: %d %v", resp.Status, resp.Body)
+	}
+
+	pr := doJSON(t, engine, http.MethodGet, "/pullRequest/get?pull_request_id=acme/api%2342", nil)
+	if pr.Status != http.StatusOK {
+		t.Fatalf("get PR: %d %v", pr.Status, pr.Body)
+	}
+	if author := pr.Body["pr"].(map[string]any)["author_id"]; author != "u2" {
+		t.Errorf("expected author u2, got %v", author)
+	}
+
+	// Повтор той же доставки не обрабатывается
+	resp = sendGitHubEvent(t, engine, "d-1", "", pullRequestEvent("opened", false))
+	if resp.Status != http.StatusOK || resp.Body["status"] != "duplicate" {
+		t.Fatalf("duplicate: %d %v", resp.Status, resp.Body)
+	}
+
+	resp = sendGitHubEvent(t, engine, "d-2", "", pullRequestEvent("closed", true))
+	if resp.Status != http.StatusOK || resp.Body["status"] != "processed" {
+		t.Fatalf("merged: %d %v", resp.Status, resp.Body)
+	}
+	pr = doJSON(t, engine, http.MethodGet, "/pullRequest/get?pull_request_id=acme/api%2342", nil)
+	if status := pr.Body["pr"].(map[string]any)["status"]; status != "MERGED" {
+		t.Errorf("expected MERGED, got %v", status)
+	}
+}
+
+func TestGitHubWebhookUnknownLogin(t *testing.T) {
+	engine, _ := setupServer(t)
+	createTeamAndPR(t, engine, "pr-1", 4)
+
+	resp := sendGitHubEvent(t, engine, "d-1", "", pullRequestEvent("opened", false))
+	if resp.Status != http.StatusUnprocessableEntity || resp.errorCode() != "UNKNOWN_LOGIN" {
+		t.Fatalf("expected UNKNOWN_LOGIN, got %d %v", resp.Status, resp.Body)
+	}
+
+	// Неудачная доставка забывается: после привязки логина повтор проходит
+	if resp := doJSON(t, engine, http.MethodPost, "/integrations/github/identities", map[string]any{
+		"login":   "octocat",
+		"user_id": "u2",
+	}); resp.Status != http.StatusOK {
+		t.Fatalf("map identity: %d %v", resp.Status, resp.Body)
+	}
+	resp = sendGitHubEvent(t, engine, "d-1", "", pullRequestEvent("opened", false))
+	if resp.Status != http.StatusOK || resp.Body["status"] != "processed" {
+		t.Fatalf("retry: %d %v", resp.Status, resp.Body)
+	}
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/danonenka/PR-service/internal/delivery/http/middleware"
+	"github.com/danonenka/PR-service/internal/usecase"
+	"github.com/danonenka/PR-service/internal/webhook"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	githubSignatureHeader = "X-Hub-Signature-256"
+	githubEventHeader     = "X-GitHub-Event"
+	githubDeliveryHeader  = "X-GitHub-Delivery"
+)
+
+type GitHubHandler struct {
+	githubUsecase *usecase.GitHubUsecase
+	// secret — секрет вебхука, заданный в настройках репозитория; без него
+	// все входящие вебхуки отклоняются
+	secret string
+}
+
+func NewGitHubHandler(githubUsecase *usecase.GitHubUsecase, secret string) *GitHubHandler {
+	return &GitHubHandler{githubUsecase: githubUsecase, secret: secret}
+}
+
+type MapIdentityRequest struct {
+	Login  string `json:"login" binding:"required"`
+	UserID string `json:"user_id" binding:"required"`
+}
+
+type IdentityResponse struct {
+	Login  string `json:"login"`
+	UserID string `json:"user_id"`
+}
+
+func (h *GitHubHandler) Webhook(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		respondInvalidRequest(c, err.Error())
+		return
+	}
+	if !webhook.Verify(h.secret, body, c.GetHeader(githubSignatureHeader)) {
+		respondError(c, fmt.Errorf("missing or invalid %s: %w", githubSignatureHeader, middleware.ErrInvalidSignature))
+		return
+	}
+
+	switch c.GetHeader(githubEventHeader) {
+	case "ping":
+		c.JSON(http.StatusOK, gin.H{"status": "pong"})
+		return
+	case "pull_request":
+	default:
+		c.JSON(http.StatusOK, gin.H{"status": usecase.GitHubResultIgnored})
+		return
+	}
+
+	deliveryID := c.GetHeader(githubDeliveryHeader)
+	if deliveryID == "" {
+		respondInvalidRequest(c, githubDeliveryHeader+" header is required")
+		return
+	}
+	var event usecase.GitHubPullRequestEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		respondInvalidRequest(c, err.Error())
+		return
+	}
+	if event.Repository.FullName == "" || event.Number <= 0 {
+		respondInvalidRequest(c, "repository.full_name and number are required")
+		return
+	}
+
+	result, err := h.githubUsecase.HandlePullRequestEvent(c.Request.Context(), deliveryID, &event)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":          result,
+		"pull_request_id": event.PRID(),
+	})
+}
+
+func (h *GitHubHandler) MapIdentity(c *gin.Context) {
+	var req MapIdentityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidRequest(c, err.Error())
+		return
+	}
+
+	mapping, err := h.githubUsecase.MapIdentity(c.Request.Context(), req.Login, req.UserID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"identity": IdentityResponse{Login: mapping.Login, UserID: mapping.UserID},
+	})
+}
+
+func (h *GitHubHandler) ListIdentities(c *gin.Context) {
+	mappings, err := h.githubUsecase.ListIdentities(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	identities := make([]IdentityResponse, 0, len(mappings))
+	for _, mapping := range mappings {
+		identities = append(identities, IdentityResponse{Login: mapping.Login, UserID: mapping.UserID})
+	}
+	c.JSON(http.StatusOK, gin.H{"identities": identities})
+}
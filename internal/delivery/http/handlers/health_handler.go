@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readinessTimeout ограничивает все проверки одного запроса /readyz:
+// зависшая база не должна держать пробу дольше таймаута оркестратора.
+const readinessTimeout = 2 * time.Second
+
+// ReadinessCheck возвращает ошибку, если зависимость сервиса недоступна.
+type ReadinessCheck func(ctx context.Context) error
+
+type HealthHandler struct {
+	checks   map[string]ReadinessCheck
+	draining atomic.Bool
+}
+
+func NewHealthHandler(checks map[string]ReadinessCheck) *HealthHandler {
+	return &HealthHandler{checks: checks}
+}
+
+// SetDraining переводит /readyz в 503, чтобы балансировщик перестал
+// присылать новые запросы, пока сервер дорабатывает текущие.
+func (h *HealthHandler) SetDraining() {
+	h.draining.Store(true)
+}
+
+// Livez отвечает 200, пока процесс жив; зависимости не проверяются,
+// иначе недоступная база приводила бы к перезапуску всех реплик.
+func (h *HealthHandler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	if h.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "draining", "checks": gin.H{}})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+
+	status := http.StatusOK
+	results := make(map[string]string, len(h.checks))
+	for name, check := range h.checks {
+		if err := check(ctx); err != nil {
+			status = http.StatusServiceUnavailable
+			results[name] = err.Error()
+			continue
+		}
+		results[name] = "ok"
+	}
+
+	if status != http.StatusOK {
+		c.JSON(status, gin.H{"status": "unavailable", "checks": results})
+		return
+	}
+	c.JSON(status, gin.H{"status": "ok", "checks": results})
+}
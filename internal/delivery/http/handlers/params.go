@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/danonenka/PR-service/internal/auth"
+	"github.com/danonenka/PR-service/internal/delivery/http/middleware"
+	"github.com/danonenka/PR-service/internal/domain"
+	"github.com/danonenka/PR-service/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// actorHeader — кто выполняет запрос, если аутентификация отключена;
+// значение попадает в журнал изменений PR.
+const actorHeader = "X-Actor-ID"
+
+// actorID — инициатор изменения: владелец токена или, без аутентификации,
+// значение X-Actor-ID.
+func actorID(c *gin.Context) string {
+	if principal := middleware.PrincipalFrom(c); principal != nil {
+		return principal.Subject
+	}
+	return c.GetHeader(actorHeader)
+}
+
+// authorizeTeam завершает запрос с 403 FORBIDDEN и возвращает false, если
+// вызывающий не может менять команду teamName: team-lead управляет только своей.
+func authorizeTeam(c *gin.Context, teamName string) bool {
+	principal := middleware.PrincipalFrom(c)
+	if principal == nil || principal.CanManageTeam(teamName) {
+		return true
+	}
+	respondError(c, fmt.Errorf("team leads can only modify their own team: %w", middleware.ErrForbidden))
+	return false
+}
+
+// authorizeActor завершает запрос с 403 FORBIDDEN и возвращает false, если
+// вызывающий пытается действовать от имени другого пользователя.
+func authorizeActor(c *gin.Context, userID string) bool {
+	principal := middleware.PrincipalFrom(c)
+	if principal == nil || principal.CanActAs(userID) {
+		return true
+	}
+	respondError(c, fmt.Errorf("cannot act on behalf of user %s: %w", userID, middleware.ErrForbidden))
+	return false
+}
+
+// authorizeUserTeam проверяет, что вызывающий может менять текущую команду
+// пользователя userID. Несуществующего пользователя пропускает: 404 или
+// создание решает обработчик; любая другая ошибка чтения запрещает запрос.
+func authorizeUserTeam(c *gin.Context, users *usecase.UserUsecase, teams *usecase.TeamUsecase, userID string) bool {
+	principal := middleware.PrincipalFrom(c)
+	if principal == nil || principal.Role == auth.RoleAdmin {
+		return true
+	}
+	user, err := users.GetUserByID(c.Request.Context(), userID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return true
+	}
+	if err != nil {
+		respondError(c, err)
+		return false
+	}
+	team, err := teams.GetTeamByID(c.Request.Context(), user.TeamID)
+	if err != nil {
+		respondError(c, err)
+		return false
+	}
+	return authorizeTeam(c, team.Name)
+}
+
+// authorizePRTeam завершает запрос с 403 FORBIDDEN и возвращает false, если
+// вызывающий не может менять PR prID. Команда PR — команда автора: team-lead
+// и бот меняют PR команды из токена, member — PR команды, в которой состоит.
+// Несуществующий PR пропускается: 404 вернёт обработчик.
+func authorizePRTeam(c *gin.Context, prs *usecase.PRUsecase, users *usecase.UserUsecase, teams *usecase.TeamUsecase, prID string) bool {
+	principal := middleware.PrincipalFrom(c)
+	if principal == nil || principal.Role == auth.RoleAdmin {
+		return true
+	}
+	ctx := c.Request.Context()
+	pr, err := prs.GetPRByID(ctx, prID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return true
+	}
+	if err != nil {
+		respondError(c, err)
+		return false
+	}
+	author, err := users.GetUserByID(ctx, pr.AuthorID)
+	if err != nil {
+		respondError(c, err)
+		return false
+	}
+
+	var allowed bool
+	if principal.Role == auth.RoleMember {
+		caller, err := users.GetUserByID(ctx, principal.Subject)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			respondError(c, err)
+			return false
+		}
+		allowed = err == nil && caller.TeamID != "" && caller.TeamID == author.TeamID
+	} else {
+		team, err := teams.GetTeamByID(ctx, author.TeamID)
+		if err != nil {
+			respondError(c, err)
+			return false
+		}
+		allowed = principal.Team != "" && principal.Team == team.Name
+	}
+	if !allowed {
+		respondError(c, fmt.Errorf("can only modify pull requests of your own team: %w", middleware.ErrForbidden))
+	}
+	return allowed
+}
+
+// respondError передаёт ошибку middleware.ErrorHandler, который выбирает
+// HTTP-статус и код ответа по её типу.
+func respondError(c *gin.Context, err error) {
+	_ = c.Error(err)
+}
+
+func respondInvalidRequest(c *gin.Context, message string) {
+	respondError(c, domain.NewValidationError(message))
+}
+
+func parseStatusParam(value string) (domain.PRStatus, error) {
+	if value == "" {
+		return "", nil
+	}
+	if !domain.PRStatus(value).IsValid() {
+		return "", fmt.Errorf("unknown status %q", value)
+	}
+	return domain.PRStatus(value), nil
+}
+
+// parseLimitParam разбирает размер страницы; 0 означает лимит по умолчанию.
+func parseLimitParam(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("limit must be a positive integer")
+	}
+	return limit, nil
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -11,49 +13,93 @@ import (
 )
 
 type PRHandler struct {
-	prUsecase *usecase.PRUsecase
+	prUsecase   *usecase.PRUsecase
+	userUsecase *usecase.UserUsecase
+	teamUsecase *usecase.TeamUsecase
 }
 
-func NewPRHandler(prUsecase *usecase.PRUsecase) *PRHandler {
-	return &PRHandler{prUsecase: prUsecase}
+func NewPRHandler(prUsecase *usecase.PRUsecase, userUsecase *usecase.UserUsecase, teamUsecase *usecase.TeamUsecase) *PRHandler {
+	return &PRHandler{prUsecase: prUsecase, userUsecase: userUsecase, teamUsecase: teamUsecase}
+}
+
+func (h *PRHandler) authorizePR(c *gin.Context, prID string) bool {
+	return authorizePRTeam(c, h.prUsecase, h.userUsecase, h.teamUsecase, prID)
 }
 
 type CreatePRRequest struct {
 	PullRequestID   string `json:"pull_request_id" binding:"required"`
 	PullRequestName string `json:"pull_request_name" binding:"required"`
 	AuthorID        string `json:"author_id" binding:"required"`
+	// Draft создаёт черновик без ревьюеров
+	Draft bool `json:"draft"`
 }
 
 type PRResponse struct {
-	PullRequestID     string   `json:"pull_request_id"`
-	PullRequestName   string   `json:"pull_request_name"`
-	AuthorID          string   `json:"author_id"`
-	Status            string   `json:"status"`
-	AssignedReviewers []string `json:"assigned_reviewers"`
-	CreatedAt         *string  `json:"createdAt,omitempty"`
-	MergedAt          *string  `json:"mergedAt,omitempty"`
+	PullRequestID     string           `json:"pull_request_id"`
+	PullRequestName   string           `json:"pull_request_name"`
+	AuthorID          string           `json:"author_id"`
+	Status            string           `json:"status"`
+	AssignedReviewers []string         `json:"assigned_reviewers"`
+	CreatedAt         *string          `json:"createdAt,omitempty"`
+	MergedAt          *string          `json:"mergedAt,omitempty"`
+	ClosedAt          *string          `json:"closedAt,omitempty"`
+	UpdatedAt         *string          `json:"updatedAt,omitempty"`
+	RequiredReviewers int              `json:"required_reviewers"`
+	Understaffed      bool             `json:"understaffed"`
+	Reviews           []ReviewResponse `json:"reviews,omitempty"`
+}
+
+type ReviewResponse struct {
+	ReviewerID string  `json:"reviewer_id"`
+	State      string  `json:"state"`
+	AssignedAt *string `json:"assigned_at,omitempty"`
+	ReviewedAt *string `json:"reviewed_at,omitempty"`
+}
+
+func newReviewResponse(review *domain.ReviewerAssignment) ReviewResponse {
+	return ReviewResponse{
+		ReviewerID: review.ReviewerID,
+		State:      string(review.State),
+		AssignedAt: formatTime(&review.AssignedAt),
+		ReviewedAt: formatTime(review.ReviewedAt),
+	}
+}
+
+func newPRResponse(pr *domain.PullRequest) PRResponse {
+	resp := PRResponse{
+		PullRequestID:     pr.ID,
+		PullRequestName:   pr.Title,
+		AuthorID:          pr.AuthorID,
+		Status:            string(pr.Status),
+		AssignedReviewers: pr.ReviewerIDs,
+		CreatedAt:         formatTime(&pr.CreatedAt),
+		MergedAt:          formatTime(pr.MergedAt),
+		ClosedAt:          formatTime(pr.ClosedAt),
+		UpdatedAt:         formatTime(&pr.UpdatedAt),
+		RequiredReviewers: pr.RequiredReviewers,
+		Understaffed:      pr.IsUnderstaffed(),
+	}
+	if pr.Reviews != nil {
+		resp.Reviews = make([]ReviewResponse, 0, len(pr.Reviews))
+		for _, review := range pr.Reviews {
+			resp.Reviews = append(resp.Reviews, newReviewResponse(review))
+		}
+	}
+	return resp
+}
+
+func formatTime(t *time.Time) *string {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	formatted := t.UTC().Format(time.RFC3339)
+	return &formatted
 }
 
 func (h *PRHandler) CreatePR(c *gin.Context) {
 	var req CreatePRRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": gin.H{
-				"code":    "INVALID_REQUEST",
-				"message": err.Error(),
-			},
-		})
-		return
-	}
-
-	_, err := h.prUsecase.GetPRByID(req.PullRequestID)
-	if err == nil {
-		c.JSON(http.StatusConflict, gin.H{
-			"error": gin.H{
-				"code":    "PR_EXISTS",
-				"message": "PR id already exists",
-			},
-		})
+		respondInvalidRequest(c, err.Error())
 		return
 	}
 
@@ -64,47 +110,23 @@ func (h *PRHandler) CreatePR(c *gin.Context) {
 		Status:      domain.PRStatusOpen,
 		ReviewerIDs: []string{},
 	}
+	if req.Draft {
+		pr.Status = domain.PRStatusDraft
+	}
 
-	if err := h.prUsecase.CreatePR(pr); err != nil {
-		if err.Error() == "author not found" {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": gin.H{
-					"code":    "NOT_FOUND",
-					"message": "resource not found",
-				},
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": gin.H{
-				"code":    "INTERNAL_ERROR",
-				"message": err.Error(),
-			},
-		})
+	if err := h.prUsecase.CreatePR(c.Request.Context(), pr, actorID(c)); err != nil {
+		respondError(c, err)
 		return
 	}
 
-	pr, err = h.prUsecase.GetPRByID(pr.ID)
+	pr, err := h.prUsecase.GetPRByID(c.Request.Context(), pr.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": gin.H{
-				"code":    "INTERNAL_ERROR",
-				"message": err.Error(),
-			},
-		})
+		respondError(c, err)
 		return
 	}
 
-	now := time.Now().Format(time.RFC3339)
 	c.JSON(http.StatusCreated, gin.H{
-		"pr": PRResponse{
-			PullRequestID:     pr.ID,
-			PullRequestName:   pr.Title,
-			AuthorID:          pr.AuthorID,
-			Status:            string(pr.Status),
-			AssignedReviewers: pr.ReviewerIDs,
-			CreatedAt:         &now,
-		},
+		"pr": newPRResponse(pr),
 	})
 }
 
@@ -115,46 +137,26 @@ type MergePRRequest struct {
 func (h *PRHandler) MergePR(c *gin.Context) {
 	var req MergePRRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": gin.H{
-				"code":    "INVALID_REQUEST",
-				"message": err.Error(),
-			},
-		})
+		respondInvalidRequest(c, err.Error())
+		return
+	}
+	if !h.authorizePR(c, req.PullRequestID) {
 		return
 	}
 
-	if err := h.prUsecase.MergePR(req.PullRequestID); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": gin.H{
-				"code":    "NOT_FOUND",
-				"message": "resource not found",
-			},
-		})
+	if err := h.prUsecase.MergePR(c.Request.Context(), req.PullRequestID, actorID(c)); err != nil {
+		respondError(c, err)
 		return
 	}
 
-	pr, err := h.prUsecase.GetPRByID(req.PullRequestID)
+	pr, err := h.prUsecase.GetPRByID(c.Request.Context(), req.PullRequestID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": gin.H{
-				"code":    "NOT_FOUND",
-				"message": "resource not found",
-			},
-		})
+		respondError(c, err)
 		return
 	}
 
-	mergedAt := time.Now().Format(time.RFC3339)
 	c.JSON(http.StatusOK, gin.H{
-		"pr": PRResponse{
-			PullRequestID:     pr.ID,
-			PullRequestName:   pr.Title,
-			AuthorID:          pr.AuthorID,
-			Status:            string(pr.Status),
-			AssignedReviewers: pr.ReviewerIDs,
-			MergedAt:          &mergedAt,
-		},
+		"pr": newPRResponse(pr),
 	})
 }
 
@@ -166,108 +168,256 @@ type ReassignReviewerRequest struct {
 func (h *PRHandler) ReassignReviewer(c *gin.Context) {
 	var req ReassignReviewerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": gin.H{
-				"code":    "INVALID_REQUEST",
-				"message": err.Error(),
-			},
-		})
+		respondInvalidRequest(c, err.Error())
+		return
+	}
+	if !h.authorizePR(c, req.PullRequestID) {
 		return
 	}
 
-	pr, err := h.prUsecase.GetPRByID(req.PullRequestID)
+	newReviewerID, err := h.prUsecase.ReassignReviewer(c.Request.Context(), req.PullRequestID, req.OldUserID, actorID(c))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": gin.H{
-				"code":    "NOT_FOUND",
-				"message": "resource not found",
-			},
-		})
+		respondError(c, err)
 		return
 	}
 
-	if pr.Status == domain.PRStatusMerged {
-		c.JSON(http.StatusConflict, gin.H{
-			"error": gin.H{
-				"code":    "PR_MERGED",
-				"message": "cannot reassign on merged PR",
-			},
-		})
+	updatedPR, err := h.prUsecase.GetPRByID(c.Request.Context(), req.PullRequestID)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
-	found := false
-	for _, reviewerID := range pr.ReviewerIDs {
-		if reviewerID == req.OldUserID {
-			found = true
-			break
-		}
+	c.JSON(http.StatusOK, gin.H{
+		"pr":          newPRResponse(updatedPR),
+		"replaced_by": newReviewerID,
+	})
+}
+
+type PREventResponse struct {
+	ID          int64  `json:"id"`
+	Type        string `json:"type"`
+	ActorID     string `json:"actor_id,omitempty"`
+	ReviewerID  string `json:"reviewer_id,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+	FromStatus  string `json:"from_status,omitempty"`
+	ToStatus    string `json:"to_status,omitempty"`
+	ReviewState string `json:"review_state,omitempty"`
+	CreatedAt   string `json:"created_at"`
+}
+
+func (h *PRHandler) GetHistory(c *gin.Context) {
+	prID := c.Query("pull_request_id")
+	if prID == "" {
+		respondInvalidRequest(c, "pull_request_id parameter is required")
+		return
+	}
+
+	events, err := h.prUsecase.GetPRHistory(c.Request.Context(), prID)
+	if err != nil {
+		respondError(c, err)
+		return
 	}
 
-	if !found {
-		c.JSON(http.StatusConflict, gin.H{
-			"error": gin.H{
-				"code":    "NOT_ASSIGNED",
-				"message": "reviewer is not assigned to this PR",
-			},
+	eventResponses := make([]PREventResponse, 0, len(events))
+	for _, event := range events {
+		eventResponses = append(eventResponses, PREventResponse{
+			ID:          event.ID,
+			Type:        string(event.Type),
+			ActorID:     event.ActorID,
+			ReviewerID:  event.ReviewerID,
+			Reason:      string(event.Reason),
+			FromStatus:  string(event.FromStatus),
+			ToStatus:    string(event.ToStatus),
+			ReviewState: string(event.ReviewState),
+			CreatedAt:   event.CreatedAt.UTC().Format(time.RFC3339Nano),
 		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pull_request_id": prID,
+		"events":          eventResponses,
+	})
+}
+
+type SubmitReviewRequest struct {
+	PullRequestID string `json:"pull_request_id" binding:"required"`
+	ReviewerID    string `json:"reviewer_id" binding:"required"`
+	State         string `json:"state" binding:"required"`
+}
+
+func (h *PRHandler) SubmitReview(c *gin.Context) {
+	var req SubmitReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidRequest(c, err.Error())
 		return
 	}
 
-	// Сохраняем список ревьюеров до переназначения
-	oldReviewerIDs := make(map[string]bool)
-	for _, reviewerID := range pr.ReviewerIDs {
-		oldReviewerIDs[reviewerID] = true
+	state := domain.ReviewState(req.State)
+	if !state.IsVerdict() {
+		respondInvalidRequest(c, "state must be one of APPROVED, CHANGES_REQUESTED, COMMENTED")
+		return
+	}
+	// Иначе участник мог бы одобрить PR за другого ревьюера и набрать кворум один
+	if !authorizeActor(c, req.ReviewerID) {
+		return
 	}
 
-	err = h.prUsecase.ReassignReviewer(req.PullRequestID, req.OldUserID)
+	review, err := h.prUsecase.SubmitReview(c.Request.Context(), req.PullRequestID, req.ReviewerID, state, actorID(c))
 	if err != nil {
-		if err.Error() == "no available reviewers" {
-			c.JSON(http.StatusConflict, gin.H{
-				"error": gin.H{
-					"code":    "NO_CANDIDATE",
-					"message": "no active replacement candidate in team",
-				},
-			})
-			return
-		}
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": gin.H{
-				"code":    "NOT_FOUND",
-				"message": "resource not found",
-			},
-		})
+		respondError(c, err)
 		return
 	}
 
-	updatedPR, err := h.prUsecase.GetPRByID(req.PullRequestID)
+	pr, err := h.prUsecase.GetPRByID(c.Request.Context(), req.PullRequestID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": gin.H{
-				"code":    "INTERNAL_ERROR",
-				"message": err.Error(),
-			},
-		})
+		respondError(c, err)
 		return
 	}
 
-	// Находим нового ревьюера - того, кто есть в новом списке, но не был в старом
-	var newReviewerID string
-	for _, reviewerID := range updatedPR.ReviewerIDs {
-		if !oldReviewerIDs[reviewerID] {
-			newReviewerID = reviewerID
-			break
-		}
+	c.JSON(http.StatusOK, gin.H{
+		"pr":     newPRResponse(pr),
+		"review": newReviewResponse(review),
+	})
+}
+
+type PRStatusRequest struct {
+	PullRequestID string `json:"pull_request_id" binding:"required"`
+}
+
+func (h *PRHandler) ClosePR(c *gin.Context) {
+	h.changeStatus(c, h.prUsecase.ClosePR)
+}
+
+func (h *PRHandler) ReopenPR(c *gin.Context) {
+	h.changeStatus(c, h.prUsecase.ReopenPR)
+}
+
+func (h *PRHandler) MarkReady(c *gin.Context) {
+	h.changeStatus(c, h.prUsecase.MarkReady)
+}
+
+// changeStatus — общий обработчик close/reopen/markReady: все они принимают
+// pull_request_id и возвращают PR в новом статусе.
+func (h *PRHandler) changeStatus(c *gin.Context, change func(ctx context.Context, prID string, actorID string) error) {
+	var req PRStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidRequest(c, err.Error())
+		return
+	}
+	if !h.authorizePR(c, req.PullRequestID) {
+		return
+	}
+
+	if err := change(c.Request.Context(), req.PullRequestID, actorID(c)); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	pr, err := h.prUsecase.GetPRByID(c.Request.Context(), req.PullRequestID)
+	if err != nil {
+		respondError(c, err)
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"pr": PRResponse{
-			PullRequestID:     updatedPR.ID,
-			PullRequestName:   updatedPR.Title,
-			AuthorID:          updatedPR.AuthorID,
-			Status:            string(updatedPR.Status),
-			AssignedReviewers: updatedPR.ReviewerIDs,
-		},
-		"replaced_by": newReviewerID,
+		"pr": newPRResponse(pr),
+	})
+}
+
+func (h *PRHandler) GetPR(c *gin.Context) {
+	prID := c.Query("pull_request_id")
+	if prID == "" {
+		respondInvalidRequest(c, "pull_request_id parameter is required")
+		return
+	}
+
+	pr, err := h.prUsecase.GetPRByID(c.Request.Context(), prID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pr": newPRResponse(pr),
+	})
+}
+
+type ListPRsResponse struct {
+	PullRequests []PRResponse `json:"pull_requests"`
+	NextCursor   string       `json:"next_cursor,omitempty"`
+}
+
+func (h *PRHandler) ListPRs(c *gin.Context) {
+	query, err := parsePRListQuery(c)
+	if err != nil {
+		respondInvalidRequest(c, err.Error())
+		return
+	}
+
+	page, err := h.prUsecase.ListPRs(c.Request.Context(), query)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	prs := make([]PRResponse, 0, len(page.Items))
+	for _, pr := range page.Items {
+		prs = append(prs, newPRResponse(pr))
+	}
+
+	c.JSON(http.StatusOK, ListPRsResponse{
+		PullRequests: prs,
+		NextCursor:   page.NextCursor,
 	})
 }
+
+func parsePRListQuery(c *gin.Context) (usecase.PRListQuery, error) {
+	query := usecase.PRListQuery{
+		AuthorID:   c.Query("author_id"),
+		TeamName:   c.Query("team_name"),
+		ReviewerID: c.Query("reviewer_id"),
+		Cursor:     c.Query("cursor"),
+	}
+
+	status, err := parseStatusParam(c.Query("status"))
+	if err != nil {
+		return query, err
+	}
+	query.Status = status
+
+	limit, err := parseLimitParam(c.Query("limit"))
+	if err != nil {
+		return query, err
+	}
+	query.Limit = limit
+
+	from, err := parseTimeParam(c.Query("created_from"), false)
+	if err != nil {
+		return query, fmt.Errorf("invalid created_from: %w", err)
+	}
+	to, err := parseTimeParam(c.Query("created_to"), true)
+	if err != nil {
+		return query, fmt.Errorf("invalid created_to: %w", err)
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return query, fmt.Errorf("created_from must be before created_to")
+	}
+	query.CreatedFrom = from
+	query.CreatedTo = to
+
+	if sortBy := c.Query("sort"); sortBy != "" {
+		if !domain.PRSortField(sortBy).IsValid() {
+			return query, fmt.Errorf("unknown sort %q", sortBy)
+		}
+		query.SortBy = domain.PRSortField(sortBy)
+	}
+	switch order := c.Query("order"); order {
+	case "", "asc":
+	case "desc":
+		query.Descending = true
+	default:
+		return query, fmt.Errorf("unknown order %q", order)
+	}
+
+	return query, nil
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import "github.com/danonenka/PR-service/internal/usecase"
+
+type ReviewerReassignmentResponse struct {
+	PullRequestID string `json:"pull_request_id"`
+	OldReviewerID string `json:"old_reviewer_id"`
+	NewReviewerID string `json:"new_reviewer_id,omitempty"`
+	Understaffed  bool   `json:"understaffed"`
+	Error         string `json:"error,omitempty"`
+}
+
+type ReassignmentReportResponse struct {
+	Reassigned []ReviewerReassignmentResponse `json:"reassigned"`
+	Removed    []ReviewerReassignmentResponse `json:"removed"`
+	LeftShort  []ReviewerReassignmentResponse `json:"left_short"`
+	Failed     []ReviewerReassignmentResponse `json:"failed"`
+}
+
+func newReassignmentReportResponse(report *usecase.ReassignmentReport) *ReassignmentReportResponse {
+	resp := &ReassignmentReportResponse{
+		Reassigned: make([]ReviewerReassignmentResponse, 0),
+		Removed:    make([]ReviewerReassignmentResponse, 0),
+		LeftShort:  make([]ReviewerReassignmentResponse, 0),
+		Failed:     make([]ReviewerReassignmentResponse, 0),
+	}
+
+	for _, item := range report.Items {
+		entry := ReviewerReassignmentResponse{
+			PullRequestID: item.PRID,
+			OldReviewerID: item.OldReviewerID,
+			NewReviewerID: item.NewReviewerID,
+			Understaffed:  item.Understaffed,
+			Error:         item.Error,
+		}
+		switch item.Outcome {
+		case usecase.OutcomeReassigned:
+			resp.Reassigned = append(resp.Reassigned, entry)
+		case usecase.OutcomeRemoved:
+			resp.Removed = append(resp.Removed, entry)
+		case usecase.OutcomeLeftShort:
+			resp.LeftShort = append(resp.LeftShort, entry)
+		case usecase.OutcomeFailed:
+			resp.Failed = append(resp.Failed, entry)
+		}
+	}
+
+	return resp
+}
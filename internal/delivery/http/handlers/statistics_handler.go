@@ -1,12 +1,17 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"time"
+
 	"github.com/danonenka/PR-service/internal/usecase"
 
 	"github.com/gin-gonic/gin"
 )
 
+const dateLayout = "2006-01-02"
+
 type StatisticsHandler struct {
 	statisticsUsecase *usecase.StatisticsUsecase
 }
@@ -15,23 +20,119 @@ func NewStatisticsHandler(statisticsUsecase *usecase.StatisticsUsecase) *Statist
 	return &StatisticsHandler{statisticsUsecase: statisticsUsecase}
 }
 
+type ReviewerStatsResponse struct {
+	UserID            string `json:"user_id"`
+	Username          string `json:"username"`
+	OpenAssignments   int    `json:"open_assignments"`
+	MergedAssignments int    `json:"merged_assignments"`
+	TotalAssignments  int    `json:"total_assignments"`
+}
+
+type PRStatsResponse struct {
+	PullRequestID   string `json:"pull_request_id"`
+	PullRequestName string `json:"pull_request_name"`
+	AuthorID        string `json:"author_id"`
+	Status          string `json:"status"`
+	Assignments     int    `json:"assignments"`
+}
+
 func (h *StatisticsHandler) GetUserStats(c *gin.Context) {
-	stats, err := h.statisticsUsecase.GetUserAssignmentStats()
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		respondInvalidRequest(c, err.Error())
+		return
+	}
+
+	stats, err := h.statisticsUsecase.GetUserAssignmentStats(c.Request.Context(), filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, stats)
+	reviewers := make([]ReviewerStatsResponse, 0, len(stats))
+	for _, s := range stats {
+		reviewers = append(reviewers, ReviewerStatsResponse{
+			UserID:            s.UserID,
+			Username:          s.UserName,
+			OpenAssignments:   s.OpenAssignments,
+			MergedAssignments: s.MergedAssignments,
+			TotalAssignments:  s.Assignments,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reviewers": reviewers})
 }
 
 func (h *StatisticsHandler) GetPRStats(c *gin.Context) {
-	stats, err := h.statisticsUsecase.GetPRAssignmentStats()
+	filter, err := parseStatsFilter(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondInvalidRequest(c, err.Error())
 		return
 	}
 
-	c.JSON(http.StatusOK, stats)
+	stats, err := h.statisticsUsecase.GetPRAssignmentStats(c.Request.Context(), filter)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	prs := make([]PRStatsResponse, 0, len(stats))
+	for _, s := range stats {
+		prs = append(prs, PRStatsResponse{
+			PullRequestID:   s.PRID,
+			PullRequestName: s.PRTitle,
+			AuthorID:        s.AuthorID,
+			Status:          string(s.Status),
+			Assignments:     s.Assignments,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pull_requests": prs})
 }
 
+func parseStatsFilter(c *gin.Context) (usecase.StatsFilter, error) {
+	filter := usecase.StatsFilter{
+		TeamName: c.Query("team_name"),
+	}
+
+	status, err := parseStatusParam(c.Query("status"))
+	if err != nil {
+		return filter, err
+	}
+	filter.Status = status
+
+	from, err := parseTimeParam(c.Query("from"), false)
+	if err != nil {
+		return filter, fmt.Errorf("invalid from: %w", err)
+	}
+	to, err := parseTimeParam(c.Query("to"), true)
+	if err != nil {
+		return filter, fmt.Errorf("invalid to: %w", err)
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return filter, fmt.Errorf("from must be before to")
+	}
+	filter.From = from
+	filter.To = to
+
+	return filter, nil
+}
+
+// parseTimeParam принимает RFC3339 или дату YYYY-MM-DD. Для верхней границы
+// дата без времени включает весь день.
+func parseTimeParam(value string, upperBound bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return nil, fmt.Errorf("expected RFC3339 timestamp or YYYY-MM-DD date")
+	}
+	if upperBound {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
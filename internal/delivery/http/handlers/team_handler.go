@@ -1,19 +1,23 @@
 package handlers
 
 import (
-	"net/http"
 	"github.com/danonenka/PR-service/internal/domain"
 	"github.com/danonenka/PR-service/internal/usecase"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
 type TeamHandler struct {
 	teamUsecase *usecase.TeamUsecase
+	userUsecase *usecase.UserUsecase
 }
 
-func NewTeamHandler(teamUsecase *usecase.TeamUsecase) *TeamHandler {
-	return &TeamHandler{teamUsecase: teamUsecase}
+func NewTeamHandler(teamUsecase *usecase.TeamUsecase, userUsecase *usecase.UserUsecase) *TeamHandler {
+	return &TeamHandler{
+		teamUsecase: teamUsecase,
+		userUsecase: userUsecase,
+	}
 }
 
 type TeamMemberRequest struct {
@@ -23,8 +27,9 @@ type TeamMemberRequest struct {
 }
 
 type TeamRequest struct {
-	TeamName string              `json:"team_name" binding:"required"`
-	Members  []TeamMemberRequest `json:"members" binding:"required"`
+	TeamName         string              `json:"team_name" binding:"required"`
+	Members          []TeamMemberRequest `json:"members" binding:"required"`
+	ReviewerStrategy string              `json:"reviewer_strategy"`
 }
 
 type TeamMemberResponse struct {
@@ -34,19 +39,51 @@ type TeamMemberResponse struct {
 }
 
 type TeamResponse struct {
-	TeamName string               `json:"team_name"`
-	Members  []TeamMemberResponse `json:"members"`
+	TeamName          string               `json:"team_name"`
+	Members           []TeamMemberResponse `json:"members"`
+	ReviewerStrategy  string               `json:"reviewer_strategy"`
+	MinReviewers      int                  `json:"min_reviewers"`
+	MaxReviewers      int                  `json:"max_reviewers"`
+	RequiredApprovals int                  `json:"required_approvals"`
+}
+
+type TeamSettingsRequest struct {
+	TeamName          string  `json:"team_name" binding:"required"`
+	ReviewerStrategy  *string `json:"reviewer_strategy"`
+	MinReviewers      *int    `json:"min_reviewers"`
+	MaxReviewers      *int    `json:"max_reviewers"`
+	RequiredApprovals *int    `json:"required_approvals"`
+}
+
+type TeamSettingsResponse struct {
+	TeamName          string `json:"team_name"`
+	ReviewerStrategy  string `json:"reviewer_strategy"`
+	MinReviewers      int    `json:"min_reviewers"`
+	MaxReviewers      int    `json:"max_reviewers"`
+	RequiredApprovals int    `json:"required_approvals"`
 }
 
 func (h *TeamHandler) AddTeam(c *gin.Context) {
 	var req TeamRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": gin.H{
-				"code":    "INVALID_REQUEST",
-				"message": err.Error(),
-			},
-		})
+		respondInvalidRequest(c, err.Error())
+		return
+	}
+
+	if !authorizeTeam(c, req.TeamName) {
+		return
+	}
+	// Существующие участники переводятся в команду: team-lead не может
+	// забрать или деактивировать пользователей чужой команды
+	for _, m := range req.Members {
+		if !authorizeUserTeam(c, h.userUsecase, h.teamUsecase, m.UserID) {
+			return
+		}
+	}
+
+	strategy := domain.ReviewerStrategy(req.ReviewerStrategy)
+	if strategy != "" && !strategy.IsValid() {
+		respondInvalidRequest(c, "unknown reviewer_strategy")
 		return
 	}
 
@@ -59,34 +96,15 @@ func (h *TeamHandler) AddTeam(c *gin.Context) {
 		})
 	}
 
-	team, err := h.teamUsecase.AddTeamWithMembers(req.TeamName, members)
+	team, err := h.teamUsecase.AddTeamWithMembers(c.Request.Context(), req.TeamName, strategy, members)
 	if err != nil {
-		if err.Error() == "TEAM_EXISTS" {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": gin.H{
-					"code":    "TEAM_EXISTS",
-					"message": "team_name already exists",
-				},
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": gin.H{
-				"code":    "INTERNAL_ERROR",
-				"message": err.Error(),
-			},
-		})
+		respondError(c, err)
 		return
 	}
 
-	_, updatedMembers, err := h.teamUsecase.GetTeamWithMembers(team.Name)
+	team, updatedMembers, err := h.teamUsecase.GetTeamWithMembers(c.Request.Context(), team.Name)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": gin.H{
-				"code":    "INTERNAL_ERROR",
-				"message": err.Error(),
-			},
-		})
+		respondError(c, err)
 		return
 	}
 
@@ -101,8 +119,12 @@ func (h *TeamHandler) AddTeam(c *gin.Context) {
 
 	c.JSON(http.StatusCreated, gin.H{
 		"team": TeamResponse{
-			TeamName: team.Name,
-			Members:  memberResponses,
+			TeamName:          team.Name,
+			Members:           memberResponses,
+			ReviewerStrategy:  string(team.ReviewerStrategy),
+			MinReviewers:      team.MinReviewers,
+			MaxReviewers:      team.MaxReviewers,
+			RequiredApprovals: team.RequiredApprovals,
 		},
 	})
 }
@@ -110,23 +132,13 @@ func (h *TeamHandler) AddTeam(c *gin.Context) {
 func (h *TeamHandler) GetTeam(c *gin.Context) {
 	teamName := c.Query("team_name")
 	if teamName == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": gin.H{
-				"code":    "INVALID_REQUEST",
-				"message": "team_name parameter is required",
-			},
-		})
+		respondInvalidRequest(c, "team_name parameter is required")
 		return
 	}
 
-	team, members, err := h.teamUsecase.GetTeamWithMembers(teamName)
+	team, members, err := h.teamUsecase.GetTeamWithMembers(c.Request.Context(), teamName)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": gin.H{
-				"code":    "NOT_FOUND",
-				"message": "resource not found",
-			},
-		})
+		respondError(c, err)
 		return
 	}
 
@@ -140,7 +152,92 @@ func (h *TeamHandler) GetTeam(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, TeamResponse{
-		TeamName: team.Name,
-		Members:  memberResponses,
+		TeamName:          team.Name,
+		Members:           memberResponses,
+		ReviewerStrategy:  string(team.ReviewerStrategy),
+		MinReviewers:      team.MinReviewers,
+		MaxReviewers:      team.MaxReviewers,
+		RequiredApprovals: team.RequiredApprovals,
+	})
+}
+
+func (h *TeamHandler) UpdateSettings(c *gin.Context) {
+	var req TeamSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidRequest(c, err.Error())
+		return
+	}
+
+	if !authorizeTeam(c, req.TeamName) {
+		return
+	}
+
+	settings := usecase.TeamSettings{
+		MinReviewers:      req.MinReviewers,
+		MaxReviewers:      req.MaxReviewers,
+		RequiredApprovals: req.RequiredApprovals,
+	}
+	if req.ReviewerStrategy != nil {
+		strategy := domain.ReviewerStrategy(*req.ReviewerStrategy)
+		settings.ReviewerStrategy = &strategy
+	}
+
+	team, err := h.teamUsecase.UpdateTeamSettings(c.Request.Context(), req.TeamName, settings)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"team": TeamSettingsResponse{
+			TeamName:          team.Name,
+			ReviewerStrategy:  string(team.ReviewerStrategy),
+			MinReviewers:      team.MinReviewers,
+			MaxReviewers:      team.MaxReviewers,
+			RequiredApprovals: team.RequiredApprovals,
+		},
+	})
+}
+
+type DeactivateUsersRequest struct {
+	TeamName string   `json:"team_name" binding:"required"`
+	UserIDs  []string `json:"user_ids" binding:"required"`
+}
+
+type DeactivateUsersResponse struct {
+	TeamName           string                      `json:"team_name"`
+	DeactivatedUserIDs []string                    `json:"deactivated_user_ids"`
+	SkippedUserIDs     []string                    `json:"skipped_user_ids"`
+	Reassignment       *ReassignmentReportResponse `json:"reassignment"`
+}
+
+func (h *TeamHandler) DeactivateUsers(c *gin.Context) {
+	var req DeactivateUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidRequest(c, err.Error())
+		return
+	}
+
+	if !authorizeTeam(c, req.TeamName) {
+		return
+	}
+
+	team, err := h.teamUsecase.GetTeamByName(c.Request.Context(), req.TeamName)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	result, err := h.userUsecase.DeactivateUsers(c.Request.Context(), team.ID, req.UserIDs, actorID(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, DeactivateUsersResponse{
+		TeamName:           team.Name,
+		DeactivatedUserIDs: result.DeactivatedUserIDs,
+		SkippedUserIDs:     result.SkippedUserIDs,
+		Reassignment:       newReassignmentReportResponse(result.Report),
 	})
 }
@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"net/http"
+
 	"github.com/danonenka/PR-service/internal/usecase"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -35,45 +37,38 @@ type UserResponse struct {
 func (h *UserHandler) SetIsActive(c *gin.Context) {
 	var req SetIsActiveRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": gin.H{
-				"code":    "INVALID_REQUEST",
-				"message": err.Error(),
-			},
-		})
+		respondInvalidRequest(c, err.Error())
+		return
+	}
+
+	if !authorizeUserTeam(c, h.userUsecase, h.teamUsecase, req.UserID) {
 		return
 	}
 
-	user, err := h.userUsecase.SetUserIsActive(req.UserID, req.IsActive)
+	user, report, err := h.userUsecase.SetUserIsActive(c.Request.Context(), req.UserID, req.IsActive, actorID(c))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": gin.H{
-				"code":    "NOT_FOUND",
-				"message": "resource not found",
-			},
-		})
+		respondError(c, err)
 		return
 	}
 
-	team, err := h.teamUsecase.GetTeamByID(user.TeamID)
+	team, err := h.teamUsecase.GetTeamByID(c.Request.Context(), user.TeamID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": gin.H{
-				"code":    "INTERNAL_ERROR",
-				"message": err.Error(),
-			},
-		})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	resp := gin.H{
 		"user": UserResponse{
 			UserID:   user.ID,
 			Username: user.Name,
 			TeamName: team.Name,
 			IsActive: user.IsActive,
 		},
-	})
+	}
+	if report != nil {
+		resp["reassignment"] = newReassignmentReportResponse(report)
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
 type PullRequestShortResponse struct {
@@ -84,35 +79,37 @@ type PullRequestShortResponse struct {
 }
 
 type GetReviewResponse struct {
-	UserID       string                      `json:"user_id"`
+	UserID       string                     `json:"user_id"`
 	PullRequests []PullRequestShortResponse `json:"pull_requests"`
+	NextCursor   string                     `json:"next_cursor,omitempty"`
 }
 
 func (h *UserHandler) GetReview(c *gin.Context) {
 	userID := c.Query("user_id")
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": gin.H{
-				"code":    "INVALID_REQUEST",
-				"message": "user_id parameter is required",
-			},
-		})
+		respondInvalidRequest(c, "user_id parameter is required")
 		return
 	}
 
-	prs, err := h.prUsecase.GetPRsByReviewerID(userID)
+	status, err := parseStatusParam(c.Query("status"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": gin.H{
-				"code":    "INTERNAL_ERROR",
-				"message": err.Error(),
-			},
-		})
+		respondInvalidRequest(c, err.Error())
+		return
+	}
+	limit, err := parseLimitParam(c.Query("limit"))
+	if err != nil {
+		respondInvalidRequest(c, err.Error())
+		return
+	}
+
+	page, err := h.prUsecase.GetReviewPage(c.Request.Context(), userID, status, limit, c.Query("cursor"))
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
-	prResponses := make([]PullRequestShortResponse, 0, len(prs))
-	for _, pr := range prs {
+	prResponses := make([]PullRequestShortResponse, 0, len(page.Items))
+	for _, pr := range page.Items {
 		prResponses = append(prResponses, PullRequestShortResponse{
 			PullRequestID:   pr.ID,
 			PullRequestName: pr.Title,
@@ -124,5 +121,6 @@ func (h *UserHandler) GetReview(c *gin.Context) {
 	c.JSON(http.StatusOK, GetReviewResponse{
 		UserID:       userID,
 		PullRequests: prResponses,
+		NextCursor:   page.NextCursor,
 	})
 }
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/danonenka/PR-service/internal/domain"
+	"github.com/danonenka/PR-service/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	webhookUsecase *usecase.WebhookUsecase
+}
+
+func NewWebhookHandler(webhookUsecase *usecase.WebhookUsecase) *WebhookHandler {
+	return &WebhookHandler{webhookUsecase: webhookUsecase}
+}
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" binding:"required"`
+	Secret     string   `json:"secret"`
+	TeamName   string   `json:"team_name"`
+	EventTypes []string `json:"event_types"`
+}
+
+type DeleteWebhookRequest struct {
+	WebhookID string `json:"webhook_id" binding:"required"`
+}
+
+type WebhookResponse struct {
+	WebhookID  string   `json:"webhook_id"`
+	URL        string   `json:"url"`
+	TeamID     string   `json:"team_id,omitempty"`
+	EventTypes []string `json:"event_types"`
+	CreatedAt  string   `json:"created_at"`
+	// Secret отдаётся только при создании подписки
+	Secret string `json:"secret,omitempty"`
+}
+
+type WebhookDeliveryResponse struct {
+	DeliveryID     int64           `json:"delivery_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *string         `json:"next_attempt_at,omitempty"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	CreatedAt      string          `json:"created_at"`
+	DeliveredAt    *string         `json:"delivered_at,omitempty"`
+	Payload        json.RawMessage `json:"payload"`
+}
+
+func newWebhookResponse(subscription *domain.WebhookSubscription) WebhookResponse {
+	eventTypes := make([]string, 0, len(subscription.EventTypes))
+	for _, t := range subscription.EventTypes {
+		eventTypes = append(eventTypes, string(t))
+	}
+	return WebhookResponse{
+		WebhookID:  subscription.ID,
+		URL:        subscription.URL,
+		TeamID:     subscription.TeamID,
+		EventTypes: eventTypes,
+		CreatedAt:  subscription.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidRequest(c, err.Error())
+		return
+	}
+
+	eventTypes := make([]domain.WebhookEventType, 0, len(req.EventTypes))
+	for _, t := range req.EventTypes {
+		eventTypes = append(eventTypes, domain.WebhookEventType(t))
+	}
+
+	subscription, err := h.webhookUsecase.CreateSubscription(c.Request.Context(), usecase.WebhookSubscriptionRequest{
+		URL:        req.URL,
+		Secret:     req.Secret,
+		TeamName:   req.TeamName,
+		EventTypes: eventTypes,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response := newWebhookResponse(subscription)
+	response.Secret = subscription.Secret
+	c.JSON(http.StatusCreated, gin.H{"webhook": response})
+}
+
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	subscriptions, err := h.webhookUsecase.ListSubscriptions(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	webhooks := make([]WebhookResponse, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		webhooks = append(webhooks, newWebhookResponse(subscription))
+	}
+	c.JSON(http.StatusOK, gin.H{"webhooks": webhooks})
+}
+
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	var req DeleteWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidRequest(c, err.Error())
+		return
+	}
+
+	if err := h.webhookUsecase.DeleteSubscription(c.Request.Context(), req.WebhookID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	webhookID := c.Query("webhook_id")
+	if webhookID == "" {
+		respondInvalidRequest(c, "webhook_id parameter is required")
+		return
+	}
+	status := domain.WebhookDeliveryStatus(c.Query("status"))
+	switch status {
+	case "", domain.WebhookDeliveryPending, domain.WebhookDeliveryDelivered, domain.WebhookDeliveryFailed:
+	default:
+		respondInvalidRequest(c, "unknown status "+string(status))
+		return
+	}
+	limit, err := parseLimitParam(c.Query("limit"))
+	if err != nil {
+		respondInvalidRequest(c, err.Error())
+		return
+	}
+
+	deliveries, err := h.webhookUsecase.ListDeliveries(c.Request.Context(), webhookID, status, limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	responses := make([]WebhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		response := WebhookDeliveryResponse{
+			DeliveryID:     delivery.ID,
+			EventID:        delivery.EventID,
+			EventType:      string(delivery.EventType),
+			Status:         string(delivery.Status),
+			Attempts:       delivery.Attempts,
+			LastStatusCode: delivery.LastStatusCode,
+			LastError:      delivery.LastError,
+			CreatedAt:      delivery.CreatedAt.UTC().Format(time.RFC3339),
+			DeliveredAt:    formatTime(delivery.DeliveredAt),
+			Payload:        json.RawMessage(delivery.Payload),
+		}
+		if delivery.Status == domain.WebhookDeliveryPending {
+			response.NextAttemptAt = formatTime(&delivery.NextAttemptAt)
+		}
+		responses = append(responses, response)
+	}
+	c.JSON(http.StatusOK, gin.H{"webhook_id": webhookID, "deliveries": responses})
+}
@@ -0,0 +1,60 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danonenka/PR-service/internal/delivery/http/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestReadyz(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var dbErr error
+	health := handlers.NewHealthHandler(map[string]handlers.ReadinessCheck{
+		"database":   func(context.Context) error { return dbErr },
+		"migrations": func(context.Context) error { return nil },
+	})
+	engine := gin.New()
+	engine.GET("/livez", health.Livez)
+	engine.GET("/readyz", health.Readyz)
+
+	probe := func(path string) (int, map[string]any) {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		var body map[string]any
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode %s: %v", path, err)
+		}
+		return w.Code, body
+	}
+
+	if status, body := probe("/readyz"); status != http.StatusOK || body["status"] != "ok" {
+		t.Errorf("healthy: got %d %v", status, body)
+	}
+
+	dbErr = errors.New("connection refused")
+	status, body := probe("/readyz")
+	if status != http.StatusServiceUnavailable {
+		t.Errorf("db down: expected 503, got %d", status)
+	}
+	checks, _ := body["checks"].(map[string]any)
+	if checks["database"] != "connection refused" || checks["migrations"] != "ok" {
+		t.Errorf("db down: unexpected checks %v", checks)
+	}
+	if status, _ := probe("/livez"); status != http.StatusOK {
+		t.Errorf("livez must not depend on the database, got %d", status)
+	}
+
+	dbErr = nil
+	health.SetDraining()
+	if status, body := probe("/readyz"); status != http.StatusServiceUnavailable || body["status"] != "draining" {
+		t.Errorf("draining: got %d %v", status, body)
+	}
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/danonenka/PR-service/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+const principalKey = "principal"
+
+// Authorize проверяет Bearer-токен запроса и роль его владельца. Без
+// токена запрос завершается ErrUnauthorized, с ролью не из roles —
+// ErrForbidden. Если authenticator == nil, проверка отключена.
+func Authorize(authenticator *auth.Authenticator, roles ...auth.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authenticator == nil {
+			c.Next()
+			return
+		}
+
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok {
+			abort(c, fmt.Errorf("missing bearer token: %w", ErrUnauthorized))
+			return
+		}
+		principal, err := authenticator.Authenticate(strings.TrimSpace(token))
+		if err != nil {
+			abort(c, fmt.Errorf("invalid or expired token: %w", ErrUnauthorized))
+			return
+		}
+		if !hasRole(principal.Role, roles) {
+			abort(c, fmt.Errorf("role %s is not allowed to call this endpoint: %w", principal.Role, ErrForbidden))
+			return
+		}
+
+		c.Set(principalKey, principal)
+		c.Next()
+	}
+}
+
+// PrincipalFrom возвращает владельца токена или nil, если проверка отключена.
+func PrincipalFrom(c *gin.Context) *auth.Principal {
+	value, ok := c.Get(principalKey)
+	if !ok {
+		return nil
+	}
+	principal, _ := value.(*auth.Principal)
+	return principal
+}
+
+func hasRole(role auth.Role, roles []auth.Role) bool {
+	for _, allowed := range roles {
+		if role == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// abort прерывает цепочку; ответ формирует ErrorHandler.
+func abort(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
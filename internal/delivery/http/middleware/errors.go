@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/danonenka/PR-service/internal/domain"
+	"github.com/danonenka/PR-service/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	// ErrInvalidSignature — входящий вебхук без подписи или с неверной
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// internalErrorMessage заменяет текст неизвестных ошибок в ответе 500.
+const internalErrorMessage = "internal error"
+
+// errorMapping — соответствие ошибки HTTP-статусу и коду в теле ответа.
+type errorMapping struct {
+	err    error
+	status int
+	code   string
+}
+
+// errorMappings проверяются по порядку, поэтому частные ошибки стоят
+// раньше категорий, которые они оборачивают (ErrTeamExists — ErrConflict).
+var errorMappings = []errorMapping{
+	{ErrUnauthorized, http.StatusUnauthorized, "UNAUTHORIZED"},
+	{ErrForbidden, http.StatusForbidden, "FORBIDDEN"},
+	{ErrInvalidSignature, http.StatusUnauthorized, "INVALID_SIGNATURE"},
+	{domain.ErrConcurrentModification, http.StatusConflict, "CONCURRENT_MODIFICATION"},
+	{domain.ErrInvalidTransition, http.StatusConflict, "INVALID_TRANSITION"},
+	{domain.ErrNotApproved, http.StatusConflict, "NOT_APPROVED"},
+	{domain.ErrPRMerged, http.StatusConflict, "PR_MERGED"},
+	{domain.ErrPRNotOpen, http.StatusConflict, "PR_NOT_OPEN"},
+	{domain.ErrNotAssigned, http.StatusConflict, "NOT_ASSIGNED"},
+	{domain.ErrNoCandidate, http.StatusConflict, "NO_CANDIDATE"},
+	{usecase.ErrUnknownLogin, http.StatusUnprocessableEntity, "UNKNOWN_LOGIN"},
+	{domain.ErrTeamExists, http.StatusBadRequest, "TEAM_EXISTS"},
+	{domain.ErrPRExists, http.StatusConflict, "PR_EXISTS"},
+	{domain.ErrNotFound, http.StatusNotFound, "NOT_FOUND"},
+	{domain.ErrConflict, http.StatusConflict, "CONFLICT"},
+	{domain.ErrInvalidArgument, http.StatusBadRequest, "INVALID_REQUEST"},
+}
+
+// ErrorHandler отвечает на ошибку, добавленную обработчиком через
+// c.Error, конвертом {"error":{"code","message"}}. Неизвестные ошибки
+// дают 500 INTERNAL_ERROR без подробностей.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		err := c.Errors.Last().Err
+		status, code := http.StatusInternalServerError, "INTERNAL_ERROR"
+		for _, mapping := range errorMappings {
+			if errors.Is(err, mapping.err) {
+				status, code = mapping.status, mapping.code
+				break
+			}
+		}
+
+		c.Set(errorCodeKey, code)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			// Текст драйвера и SQL клиенту не отдаём, он есть в логе с request ID
+			slog.ErrorContext(c.Request.Context(), "request failed", "error", err)
+			message = internalErrorMessage
+		}
+		c.JSON(status, gin.H{
+			"error": gin.H{
+				"code":    code,
+				"message": message,
+			},
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danonenka/PR-service/internal/delivery/http/middleware"
+	"github.com/danonenka/PR-service/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestErrorHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"not found", domain.NewNotFoundError("PR"), http.StatusNotFound, "NOT_FOUND"},
+		{"wrapped not found", fmt.Errorf("load author: %w", domain.ErrNotFound), http.StatusNotFound, "NOT_FOUND"},
+		{"team exists", domain.ErrTeamExists, http.StatusBadRequest, "TEAM_EXISTS"},
+		{"pr exists", domain.ErrPRExists, http.StatusConflict, "PR_EXISTS"},
+		{"other conflict", fmt.Errorf("insert: %w", domain.ErrConflict), http.StatusConflict, "CONFLICT"},
+		{"merged", fmt.Errorf("cannot reassign reviewers: %w", domain.ErrPRMerged), http.StatusConflict, "PR_MERGED"},
+		{"no candidate", domain.ErrNoCandidate, http.StatusConflict, "NO_CANDIDATE"},
+		{"not assigned", domain.ErrNotAssigned, http.StatusConflict, "NOT_ASSIGNED"},
+		{"validation", domain.NewValidationError("limit must be positive"), http.StatusBadRequest, "INVALID_REQUEST"},
+		{"forbidden", fmt.Errorf("not your team: %w", middleware.ErrForbidden), http.StatusForbidden, "FORBIDDEN"},
+		{"invalid signature", fmt.Errorf("missing X-Hub-Signature-256: %w", middleware.ErrInvalidSignature), http.StatusUnauthorized, "INVALID_SIGNATURE"},
+		{"unknown", errors.New(`pq: relation "pull_requests" does not exist`), http.StatusInternalServerError, "INTERNAL_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := gin.New()
+			engine.Use(middleware.ErrorHandler())
+			engine.GET("/", func(c *gin.Context) {
+				_ = c.Error(tt.err)
+			})
+
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			var body struct {
+				Error struct {
+					Code    string `json:"code"`
+					Message string `json:"message"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode %q: %v", rec.Body.String(), err)
+			}
+			if body.Error.Code != tt.code {
+				t.Errorf("code = %q, want %q", body.Error.Code, tt.code)
+			}
+			// Текст внутренних ошибок клиенту не отдаётся
+			want := tt.err.Error()
+			if tt.status == http.StatusInternalServerError {
+				want = "internal error"
+			}
+			if body.Error.Message != want {
+				t.Errorf("message = %q, want %q", body.Error.Message, want)
+			}
+		})
+	}
+}
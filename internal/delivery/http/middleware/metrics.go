@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errorCodeKey — под этим ключом ErrorHandler оставляет в gin.Context код
+// ошибки ответа, чтобы его учёл RequestMetrics.
+const errorCodeKey = "error_code"
+
+// unmatchedRoute — метка для запросов к несуществующим маршрутам, чтобы
+// произвольные пути не раздували число временных рядов.
+const unmatchedRoute = "unmatched"
+
+// RequestObserver учитывает обработанный HTTP-запрос.
+type RequestObserver interface {
+	ObserveRequest(method, route string, status int, duration time.Duration, errorCode string)
+}
+
+// RequestMetrics передаёт observer длительность, маршрут, статус и код
+// ошибки каждого запроса.
+func RequestMetrics(observer RequestObserver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		observer.ObserveRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start), c.GetString(errorCodeKey))
+	}
+}
@@ -1,7 +1,9 @@
 package http
 
 import (
+	"github.com/danonenka/PR-service/internal/auth"
 	"github.com/danonenka/PR-service/internal/delivery/http/handlers"
+	"github.com/danonenka/PR-service/internal/delivery/http/middleware"
 	"github.com/danonenka/PR-service/internal/usecase"
 
 	"github.com/gin-gonic/gin"
@@ -12,30 +14,77 @@ type Router struct {
 	teamHandler       *handlers.TeamHandler
 	prHandler         *handlers.PRHandler
 	statisticsHandler *handlers.StatisticsHandler
+	webhookHandler    *handlers.WebhookHandler
+	githubHandler     *handlers.GitHubHandler
+	authenticator     *auth.Authenticator
 }
 
+// NewRouter собирает обработчики. authenticator == nil отключает
+// аутентификацию: инициатор изменений тогда берётся из X-Actor-ID.
 func NewRouter(
 	userUsecase *usecase.UserUsecase,
 	teamUsecase *usecase.TeamUsecase,
 	prUsecase *usecase.PRUsecase,
 	statisticsUsecase *usecase.StatisticsUsecase,
+	webhookUsecase *usecase.WebhookUsecase,
+	githubUsecase *usecase.GitHubUsecase,
+	githubSecret string,
+	authenticator *auth.Authenticator,
 ) *Router {
 	return &Router{
 		userHandler:       handlers.NewUserHandler(userUsecase, prUsecase, teamUsecase),
-		teamHandler:       handlers.NewTeamHandler(teamUsecase),
-		prHandler:         handlers.NewPRHandler(prUsecase),
+		teamHandler:       handlers.NewTeamHandler(teamUsecase, userUsecase),
+		prHandler:         handlers.NewPRHandler(prUsecase, userUsecase, teamUsecase),
 		statisticsHandler: handlers.NewStatisticsHandler(statisticsUsecase),
+		webhookHandler:    handlers.NewWebhookHandler(webhookUsecase),
+		githubHandler:     handlers.NewGitHubHandler(githubUsecase, githubSecret),
+		authenticator:     authenticator,
 	}
 }
 
 func (r *Router) SetupRoutes(engine *gin.Engine) {
-	engine.POST("/team/add", r.teamHandler.AddTeam)
-	engine.GET("/team/get", r.teamHandler.GetTeam)
+	engine.Use(middleware.ErrorHandler())
 
-	engine.POST("/users/setIsActive", r.userHandler.SetIsActive)
-	engine.GET("/users/getReview", r.userHandler.GetReview)
+	// Группы задают, каким ролям доступен эндпоинт. Изменения команды и её
+	// PR не-админ может вносить только в свою, это проверяют обработчики.
+	everyone := engine.Group("", r.allow(auth.RoleAdmin, auth.RoleTeamLead, auth.RoleMember, auth.RoleBot))
+	people := engine.Group("", r.allow(auth.RoleAdmin, auth.RoleTeamLead, auth.RoleMember))
+	leads := engine.Group("", r.allow(auth.RoleAdmin, auth.RoleTeamLead))
+	admins := engine.Group("", r.allow(auth.RoleAdmin))
 
-	engine.POST("/pullRequest/create", r.prHandler.CreatePR)
-	engine.POST("/pullRequest/merge", r.prHandler.MergePR)
-	engine.POST("/pullRequest/reassign", r.prHandler.ReassignReviewer)
+	leads.POST("/team/add", r.teamHandler.AddTeam)
+	everyone.GET("/team/get", r.teamHandler.GetTeam)
+	leads.POST("/team/settings", r.teamHandler.UpdateSettings)
+	leads.POST("/team/deactivateUsers", r.teamHandler.DeactivateUsers)
+
+	leads.POST("/users/setIsActive", r.userHandler.SetIsActive)
+	everyone.GET("/users/getReview", r.userHandler.GetReview)
+
+	everyone.POST("/pullRequest/create", r.prHandler.CreatePR)
+	everyone.POST("/pullRequest/merge", r.prHandler.MergePR)
+	people.POST("/pullRequest/reassign", r.prHandler.ReassignReviewer)
+	people.POST("/pullRequest/review", r.prHandler.SubmitReview)
+	everyone.POST("/pullRequest/close", r.prHandler.ClosePR)
+	everyone.POST("/pullRequest/reopen", r.prHandler.ReopenPR)
+	everyone.POST("/pullRequest/markReady", r.prHandler.MarkReady)
+	everyone.GET("/pullRequest/get", r.prHandler.GetPR)
+	everyone.GET("/pullRequest/list", r.prHandler.ListPRs)
+	everyone.GET("/pullRequest/history", r.prHandler.GetHistory)
+
+	people.GET("/stats/reviewers", r.statisticsHandler.GetUserStats)
+	people.GET("/stats/pullRequests", r.statisticsHandler.GetPRStats)
+
+	admins.POST("/webhooks", r.webhookHandler.CreateWebhook)
+	admins.GET("/webhooks", r.webhookHandler.ListWebhooks)
+	admins.POST("/webhooks/delete", r.webhookHandler.DeleteWebhook)
+	admins.GET("/webhooks/deliveries", r.webhookHandler.ListDeliveries)
+
+	// Вебхук GitHub аутентифицируется подписью X-Hub-Signature-256, а не токеном
+	engine.POST("/integrations/github/webhook", r.githubHandler.Webhook)
+	admins.POST("/integrations/github/identities", r.githubHandler.MapIdentity)
+	admins.GET("/integrations/github/identities", r.githubHandler.ListIdentities)
+}
+
+func (r *Router) allow(roles ...auth.Role) gin.HandlerFunc {
+	return middleware.Authorize(r.authenticator, roles...)
 }
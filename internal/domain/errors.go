@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Базовые категории ошибок. Конкретные ошибки оборачивают их, поэтому
+// errors.Is(err, ErrNotFound) истинно и для NotFoundError, и для
+// ошибок репозитория о пропавшей строке.
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrConflict        = errors.New("conflict")
+	ErrInvalidArgument = errors.New("invalid argument")
+)
+
+// ErrConcurrentModification возвращается, когда запись изменили между
+// чтением и записью (версия PR не совпала).
+var ErrConcurrentModification = errors.New("concurrent modification")
+
+// ErrNotApproved — merge отклонён: у PR не набран кворум одобрений команды
+var ErrNotApproved = errors.New("PR is not approved")
+
+var (
+	ErrPRMerged    = errors.New("PR is merged")
+	ErrPRNotOpen   = errors.New("PR is not open")
+	ErrNoCandidate = errors.New("no available reviewers")
+	ErrNotAssigned = errors.New("reviewer is not assigned")
+	// ErrInvalidTransition — действие недопустимо в текущем статусе PR
+	ErrInvalidTransition = errors.New("invalid PR status transition")
+
+	ErrTeamExists = fmt.Errorf("team already exists: %w", ErrConflict)
+	ErrPRExists   = fmt.Errorf("PR id already exists: %w", ErrConflict)
+)
+
+// NotFoundError — не найдена сущность Entity. Err хранит исходную ошибку
+// репозитория, если она была.
+type NotFoundError struct {
+	Entity string
+	Err    error
+}
+
+func NewNotFoundError(entity string) *NotFoundError {
+	return &NotFoundError{Entity: entity}
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Entity + " not found"
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError — некорректные входные данные; Error() — сообщение для
+// клиента.
+type ValidationError struct {
+	Message string
+}
+
+func NewValidationError(message string) *ValidationError {
+	return &ValidationError{Message: message}
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidArgument
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const IdentityProviderGitHub = "github"
+
+// IdentityMapping связывает логин во внешней системе с пользователем.
+// Логины хранятся в нижнем регистре: GitHub их регистр не различает.
+type IdentityMapping struct {
+	Provider  string    `json:"provider"`
+	Login     string    `json:"login"`
+	UserID    string    `json:"userId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type IdentityRepository interface {
+	// Upsert создаёт соответствие или перепривязывает логин к другому пользователю
+	Upsert(ctx context.Context, mapping *IdentityMapping) error
+	GetUserID(ctx context.Context, provider, login string) (string, error)
+	GetByProvider(ctx context.Context, provider string) ([]*IdentityMapping, error)
+}
+
+type InboundDeliveryRepository interface {
+	// Claim запоминает доставку и возвращает false, если она уже была принята
+	Claim(ctx context.Context, provider, deliveryID, event string) (bool, error)
+	// Release забывает доставку, чтобы её повтор обработался заново
+	Release(ctx context.Context, provider, deliveryID string) error
+}
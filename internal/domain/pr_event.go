@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type PREventType string
+
+const (
+	PREventCreated          PREventType = "CREATED"
+	PREventReviewerAssigned PREventType = "REVIEWER_ASSIGNED"
+	PREventReviewerRemoved  PREventType = "REVIEWER_REMOVED"
+	PREventStatusChanged    PREventType = "STATUS_CHANGED"
+	PREventReviewSubmitted  PREventType = "REVIEW_SUBMITTED"
+)
+
+// AssignmentReason — почему ревьюер был назначен или снят.
+type AssignmentReason string
+
+const (
+	// AssignmentReasonAuto — автоматическое назначение при создании PR,
+	// markReady или reopen
+	AssignmentReasonAuto AssignmentReason = "auto"
+	// AssignmentReasonManualReassign — переназначение через /pullRequest/reassign
+	AssignmentReasonManualReassign AssignmentReason = "manual_reassign"
+	// AssignmentReasonDeactivation — ревьюер деактивирован
+	AssignmentReasonDeactivation AssignmentReason = "deactivation"
+)
+
+// PREvent — запись журнала изменений PR. Записи только добавляются.
+// ActorID пуст, если изменение выполнено без указания инициатора.
+// ReviewerID и Reason заполнены для событий о ревьюерах, FromStatus и
+// ToStatus — для CREATED (только ToStatus) и STATUS_CHANGED, ReviewerID и
+// ReviewState — для REVIEW_SUBMITTED.
+type PREvent struct {
+	ID          int64            `json:"id"`
+	PRID        string           `json:"prId"`
+	Type        PREventType      `json:"type"`
+	ActorID     string           `json:"actorId,omitempty"`
+	ReviewerID  string           `json:"reviewerId,omitempty"`
+	Reason      AssignmentReason `json:"reason,omitempty"`
+	FromStatus  PRStatus         `json:"fromStatus,omitempty"`
+	ToStatus    PRStatus         `json:"toStatus,omitempty"`
+	ReviewState ReviewState      `json:"reviewState,omitempty"`
+	CreatedAt   time.Time        `json:"createdAt"`
+}
+
+type PREventRepository interface {
+	Create(ctx context.Context, event *PREvent) error
+	// GetByPRID возвращает события PR в порядке записи
+	GetByPRID(ctx context.Context, prID string) ([]*PREvent, error)
+}
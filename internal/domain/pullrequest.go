@@ -1,26 +1,110 @@
 package domain
 
+import (
+	"context"
+	"time"
+)
+
 type PRStatus string
 
 const (
+	// PRStatusDraft — черновик, ревьюеры не назначаются до markReady
+	PRStatusDraft  PRStatus = "DRAFT"
 	PRStatusOpen   PRStatus = "OPEN"
 	PRStatusMerged PRStatus = "MERGED"
+	// PRStatusClosed — PR закрыт без merge и может быть переоткрыт
+	PRStatusClosed PRStatus = "CLOSED"
 )
 
+func (s PRStatus) IsValid() bool {
+	switch s {
+	case PRStatusDraft, PRStatusOpen, PRStatusMerged, PRStatusClosed:
+		return true
+	}
+	return false
+}
+
 type PullRequest struct {
-	ID          string    `json:"id"`
-	Title       string    `json:"title"`
-	AuthorID    string    `json:"authorId"`
-	Status      PRStatus  `json:"status"`
-	ReviewerIDs []string  `json:"reviewerIds"`
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	AuthorID    string     `json:"authorId"`
+	Status      PRStatus   `json:"status"`
+	ReviewerIDs []string   `json:"reviewerIds"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	MergedAt    *time.Time `json:"mergedAt,omitempty"`
+	ClosedAt    *time.Time `json:"closedAt,omitempty"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+	// Version увеличивается при каждом Update и используется для
+	// оптимистической блокировки
+	Version int64 `json:"version"`
+	// RequiredReviewers — минимум ревьюеров по настройкам команды автора,
+	// не хранится в pull_requests
+	RequiredReviewers int `json:"requiredReviewers"`
+	// Reviews — назначения с состоянием ревью; заполняется только при
+	// чтении одного PR
+	Reviews []*ReviewerAssignment `json:"reviews,omitempty"`
+}
+
+// IsUnderstaffed сообщает, что у открытого PR меньше ревьюеров, чем
+// требует команда.
+func (pr *PullRequest) IsUnderstaffed() bool {
+	return pr.Status == PRStatusOpen && len(pr.ReviewerIDs) < pr.RequiredReviewers
+}
+
+// PRSortField — поле, по которому упорядочивается список PR. При равных
+// значениях порядок определяет ID.
+type PRSortField string
+
+const (
+	PRSortCreatedAt PRSortField = "created_at"
+	PRSortUpdatedAt PRSortField = "updated_at"
+)
+
+func (f PRSortField) IsValid() bool {
+	return f == PRSortCreatedAt || f == PRSortUpdatedAt
+}
+
+// PRCursor — позиция в списке PR: значение поля сортировки и ID последнего
+// PR предыдущей страницы.
+type PRCursor struct {
+	Value time.Time
+	ID    string
+}
+
+// PRListFilter задаёт выборку для PullRequestRepository.List. Пустые поля
+// не фильтруют. TeamID — команда автора; CreatedFrom включается, CreatedTo
+// нет. Результат упорядочен по (SortBy, ID), по умолчанию по CreatedAt, и
+// начинается строго после After; Limit <= 0 означает без ограничения.
+type PRListFilter struct {
+	AuthorID    string
+	TeamID      string
+	ReviewerID  string
+	Status      PRStatus
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	SortBy      PRSortField
+	Descending  bool
+	After       *PRCursor
+	Limit       int
 }
 
 type PullRequestRepository interface {
-	Create(pr *PullRequest) error
-	GetByID(id string) (*PullRequest, error)
-	GetByAuthorID(authorID string) ([]*PullRequest, error)
-	GetByReviewerID(reviewerID string) ([]*PullRequest, error)
-	Update(pr *PullRequest) error
-	GetAll() ([]*PullRequest, error)
+	Create(ctx context.Context, pr *PullRequest) error
+	GetByID(ctx context.Context, id string) (*PullRequest, error)
+	// GetByIDForUpdate блокирует строку PR до конца транзакции
+	GetByIDForUpdate(ctx context.Context, id string) (*PullRequest, error)
+	// GetByAuthorID и GetByReviewerID возвращают PR сразу с заполненными
+	// ReviewerIDs
+	GetByAuthorID(ctx context.Context, authorID string) ([]*PullRequest, error)
+	GetByReviewerID(ctx context.Context, reviewerID string) ([]*PullRequest, error)
+	// GetOpenByReviewerIDs возвращает открытые PR, где ревьюером назначен
+	// хотя бы один из reviewerIDs, с заполненными ReviewerIDs
+	GetOpenByReviewerIDs(ctx context.Context, reviewerIDs []string) ([]*PullRequest, error)
+	// List возвращает страницу PR по фильтру с заполненными ReviewerIDs
+	List(ctx context.Context, filter PRListFilter) ([]*PullRequest, error)
+	// Update сохраняет PR, только если его версия не изменилась с момента
+	// чтения, иначе возвращает ErrConcurrentModification
+	Update(ctx context.Context, pr *PullRequest) error
+	GetAll(ctx context.Context) ([]*PullRequest, error)
 }
 
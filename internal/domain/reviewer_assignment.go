@@ -1,15 +1,65 @@
 package domain
 
+import (
+	"context"
+	"time"
+)
+
+type ReviewState string
+
+const (
+	ReviewStatePending          ReviewState = "PENDING"
+	ReviewStateApproved         ReviewState = "APPROVED"
+	ReviewStateChangesRequested ReviewState = "CHANGES_REQUESTED"
+	ReviewStateCommented        ReviewState = "COMMENTED"
+)
+
+// IsVerdict сообщает, может ли ревьюер выставить это состояние сам.
+// PENDING ставится только при назначении.
+func (s ReviewState) IsVerdict() bool {
+	switch s {
+	case ReviewStateApproved, ReviewStateChangesRequested, ReviewStateCommented:
+		return true
+	}
+	return false
+}
+
 type ReviewerAssignment struct {
-	PRID       string `json:"prId"`
-	ReviewerID string `json:"reviewerId"`
+	PRID       string      `json:"prId"`
+	ReviewerID string      `json:"reviewerId"`
+	State      ReviewState `json:"state"`
+	AssignedAt time.Time   `json:"assignedAt"`
+	ReviewedAt *time.Time  `json:"reviewedAt,omitempty"`
 }
 
-type ReviewerAssignmentRepository interface {
-	Create(assignment *ReviewerAssignment) error
-	Delete(prID string, reviewerID string) error
-	GetByPRID(prID string) ([]*ReviewerAssignment, error)
-	GetByReviewerID(reviewerID string) ([]*ReviewerAssignment, error)
-	DeleteByPRID(prID string) error
+// ApprovalQuorumMet сообщает, можно ли мержить PR с такими ревью: нужно
+// не меньше requiredApprovals одобрений и ни одного CHANGES_REQUESTED.
+// При requiredApprovals == 0 одобрения не требуются.
+func ApprovalQuorumMet(assignments []*ReviewerAssignment, requiredApprovals int) bool {
+	if requiredApprovals <= 0 {
+		return true
+	}
+	approvals := 0
+	for _, assignment := range assignments {
+		switch assignment.State {
+		case ReviewStateApproved:
+			approvals++
+		case ReviewStateChangesRequested:
+			return false
+		}
+	}
+	return approvals >= requiredApprovals
 }
 
+type ReviewerAssignmentRepository interface {
+	Create(ctx context.Context, assignment *ReviewerAssignment) error
+	Delete(ctx context.Context, prID string, reviewerID string) error
+	GetByPRID(ctx context.Context, prID string) ([]*ReviewerAssignment, error)
+	GetByReviewerID(ctx context.Context, reviewerID string) ([]*ReviewerAssignment, error)
+	DeleteByPRID(ctx context.Context, prID string) error
+	// UpdateState сохраняет State и ReviewedAt назначения
+	UpdateState(ctx context.Context, assignment *ReviewerAssignment) error
+	// CountOpenByReviewerIDs возвращает число открытых PR на каждого ревьюера.
+	// Ревьюеры без открытых PR в результат не попадают.
+	CountOpenByReviewerIDs(ctx context.Context, reviewerIDs []string) (map[string]int, error)
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// StatsFilter ограничивает выборку статистики. Пустые поля не фильтруют.
+// From/To применяются к времени создания PR, To не включается.
+type StatsFilter struct {
+	TeamID string
+	From   *time.Time
+	To     *time.Time
+	Status PRStatus
+}
+
+type ReviewerStats struct {
+	UserID            string
+	UserName          string
+	Assignments       int
+	OpenAssignments   int
+	MergedAssignments int
+}
+
+type PullRequestStats struct {
+	PRID        string
+	PRTitle     string
+	AuthorID    string
+	Status      PRStatus
+	Assignments int
+}
+
+// StatisticsRepository считает статистику на стороне базы.
+type StatisticsRepository interface {
+	// ReviewerStats агрегирует назначения по ревьюерам; TeamID фильтрует
+	// по команде ревьюера. Самые загруженные ревьюеры идут первыми.
+	ReviewerStats(ctx context.Context, filter StatsFilter) ([]*ReviewerStats, error)
+	// PullRequestStats считает ревьюеров на каждом PR; TeamID фильтрует
+	// по команде автора.
+	PullRequestStats(ctx context.Context, filter StatsFilter) ([]*PullRequestStats, error)
+}
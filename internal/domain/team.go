@@ -1,16 +1,56 @@
 package domain
 
+import "context"
+
+type ReviewerStrategy string
+
+const (
+	ReviewerStrategyRandom           ReviewerStrategy = "random"
+	ReviewerStrategyLeastOpenReviews ReviewerStrategy = "least-open-reviews"
+	ReviewerStrategyRoundRobin       ReviewerStrategy = "round-robin"
+)
+
+func (s ReviewerStrategy) IsValid() bool {
+	switch s {
+	case ReviewerStrategyRandom, ReviewerStrategyLeastOpenReviews, ReviewerStrategyRoundRobin:
+		return true
+	}
+	return false
+}
+
+const (
+	DefaultMinReviewers = 1
+	DefaultMaxReviewers = 2
+	// MaxReviewersLimit совпадает с ограничением chk_teams_reviewer_limits в БД
+	MaxReviewersLimit = 10
+)
+
 type Team struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID               string           `json:"id"`
+	Name             string           `json:"name"`
+	ReviewerStrategy ReviewerStrategy `json:"reviewerStrategy"`
+	MinReviewers     int              `json:"minReviewers"`
+	MaxReviewers     int              `json:"maxReviewers"`
+	// RequiredApprovals — сколько APPROVED нужно для merge; 0 — merge без
+	// одобрений
+	RequiredApprovals int `json:"requiredApprovals"`
 }
 
-type TeamRepository interface {
-	Create(team *Team) error
-	GetByID(id string) (*Team, error)
-	GetByName(name string) (*Team, error)
-	GetAll() ([]*Team, error)
-	Update(team *Team) error
-	Delete(id string) error
+func ValidReviewerLimits(minReviewers, maxReviewers int) bool {
+	return minReviewers >= 0 && maxReviewers >= minReviewers && maxReviewers <= MaxReviewersLimit
+}
+
+// ValidRequiredApprovals проверяет, что кворум достижим при max_reviewers
+// ревьюерах на PR.
+func ValidRequiredApprovals(requiredApprovals, maxReviewers int) bool {
+	return requiredApprovals >= 0 && requiredApprovals <= maxReviewers
 }
 
+type TeamRepository interface {
+	Create(ctx context.Context, team *Team) error
+	GetByID(ctx context.Context, id string) (*Team, error)
+	GetByName(ctx context.Context, name string) (*Team, error)
+	GetAll(ctx context.Context) ([]*Team, error)
+	Update(ctx context.Context, team *Team) error
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "context"
+
+// UnitOfWork даёт доступ к репозиториям, работающим в одной транзакции.
+type UnitOfWork interface {
+	Users() UserRepository
+	Teams() TeamRepository
+	PullRequests() PullRequestRepository
+	Assignments() ReviewerAssignmentRepository
+	Events() PREventRepository
+	WebhookSubscriptions() WebhookSubscriptionRepository
+	WebhookDeliveries() WebhookDeliveryRepository
+}
+
+type TxManager interface {
+	// WithinTx выполняет fn в транзакции: если fn вернула ошибку или
+	// запаниковала, все изменения откатываются, иначе фиксируются.
+	WithinTx(ctx context.Context, fn func(uow UnitOfWork) error) error
+}
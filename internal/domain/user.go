@@ -1,5 +1,7 @@
 package domain
 
+import "context"
+
 type User struct {
 	ID       string `json:"id"`
 	Name     string `json:"name"`
@@ -8,12 +10,11 @@ type User struct {
 }
 
 type UserRepository interface {
-	Create(user *User) error
-	GetByID(id string) (*User, error)
-	GetByTeamID(teamID string) ([]*User, error)
-	GetActiveByTeamID(teamID string) ([]*User, error)
-	Update(user *User) error
-	DeactivateUsers(teamID string, userIDs []string) error
-	GetByIDs(ids []string) ([]*User, error)
+	Create(ctx context.Context, user *User) error
+	GetByID(ctx context.Context, id string) (*User, error)
+	GetByTeamID(ctx context.Context, teamID string) ([]*User, error)
+	GetActiveByTeamID(ctx context.Context, teamID string) ([]*User, error)
+	Update(ctx context.Context, user *User) error
+	DeactivateUsers(ctx context.Context, teamID string, userIDs []string) error
+	GetByIDs(ctx context.Context, ids []string) ([]*User, error)
 }
-
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type WebhookEventType string
+
+const (
+	WebhookEventReviewerAssigned   WebhookEventType = "reviewer.assigned"
+	WebhookEventReviewerReassigned WebhookEventType = "reviewer.reassigned"
+	WebhookEventPRMerged           WebhookEventType = "pr.merged"
+)
+
+func (t WebhookEventType) IsValid() bool {
+	switch t {
+	case WebhookEventReviewerAssigned, WebhookEventReviewerReassigned, WebhookEventPRMerged:
+		return true
+	}
+	return false
+}
+
+// WebhookSubscription — получатель событий. Пустой TeamID означает все
+// команды, пустой EventTypes — все типы событий.
+type WebhookSubscription struct {
+	ID         string             `json:"id"`
+	URL        string             `json:"url"`
+	Secret     string             `json:"-"`
+	TeamID     string             `json:"teamId,omitempty"`
+	EventTypes []WebhookEventType `json:"eventTypes"`
+	CreatedAt  time.Time          `json:"createdAt"`
+}
+
+func (s *WebhookSubscription) Matches(teamID string, eventType WebhookEventType) bool {
+	if s.TeamID != "" && s.TeamID != teamID {
+		return false
+	}
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range s.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookSubscriptionRepository interface {
+	Create(ctx context.Context, subscription *WebhookSubscription) error
+	GetByID(ctx context.Context, id string) (*WebhookSubscription, error)
+	GetAll(ctx context.Context) ([]*WebhookSubscription, error)
+	Delete(ctx context.Context, id string) error
+	// GetMatching возвращает подписки, которым нужно отправить событие
+	// eventType команды teamID
+	GetMatching(ctx context.Context, teamID string, eventType WebhookEventType) ([]*WebhookSubscription, error)
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "PENDING"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "DELIVERED"
+	// WebhookDeliveryFailed — попытки исчерпаны, доставка больше не повторяется
+	WebhookDeliveryFailed WebhookDeliveryStatus = "FAILED"
+)
+
+// WebhookDelivery — запись outbox: одно событие для одной подписки.
+// Создаётся в транзакции изменения, отправляется диспетчером.
+type WebhookDelivery struct {
+	ID             int64                 `json:"id"`
+	SubscriptionID string                `json:"subscriptionId"`
+	EventID        string                `json:"eventId"`
+	EventType      WebhookEventType      `json:"eventType"`
+	Payload        []byte                `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	NextAttemptAt  time.Time             `json:"nextAttemptAt"`
+	LastStatusCode int                   `json:"lastStatusCode,omitempty"`
+	LastError      string                `json:"lastError,omitempty"`
+	CreatedAt      time.Time             `json:"createdAt"`
+	DeliveredAt    *time.Time            `json:"deliveredAt,omitempty"`
+}
+
+type WebhookDeliveryRepository interface {
+	Create(ctx context.Context, delivery *WebhookDelivery) error
+	// ClaimDue забирает до limit ожидающих доставок, у которых наступило
+	// время попытки, и откладывает их следующую попытку на lease, чтобы
+	// параллельные диспетчеры не отправили одно и то же дважды
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*WebhookDelivery, error)
+	// SaveAttempt сохраняет результат попытки: Status, Attempts,
+	// NextAttemptAt, LastStatusCode, LastError и DeliveredAt
+	SaveAttempt(ctx context.Context, delivery *WebhookDelivery) error
+	// ListBySubscription возвращает последние доставки подписки, новые первыми
+	ListBySubscription(ctx context.Context, subscriptionID string, status WebhookDeliveryStatus, limit int) ([]*WebhookDelivery, error)
+}
@@ -0,0 +1,52 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/danonenka/PR-service/internal/logging"
+)
+
+func TestLoggerAddsRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.NewLogger(&buf, slog.LevelInfo)
+
+	ctx := logging.WithRequestID(context.Background(), "req-1")
+	logger.With("component", "test").InfoContext(ctx, "hello", "pr_id", "pr-1")
+	logger.DebugContext(ctx, "hidden")
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("expected a single JSON record, got %q: %v", buf.String(), err)
+	}
+	for key, want := range map[string]string{
+		"msg":        "hello",
+		"request_id": "req-1",
+		"component":  "test",
+		"pr_id":      "pr-1",
+	} {
+		if record[key] != want {
+			t.Errorf("%s = %v, want %q", key, record[key], want)
+		}
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for value, want := range map[string]slog.Level{
+		"debug": slog.LevelDebug,
+		"INFO":  slog.LevelInfo,
+		"warn":  slog.LevelWarn,
+		"error": slog.LevelError,
+	} {
+		got, err := logging.ParseLevel(value)
+		if err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v", value, got, err, want)
+		}
+	}
+	if _, err := logging.ParseLevel("verbose"); err == nil {
+		t.Error("expected error for unknown level")
+	}
+}
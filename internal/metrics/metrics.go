@@ -0,0 +1,132 @@
+// Package metrics собирает метрики сервиса в формате Prometheus: HTTP,
+// пул соединений database/sql и доменные счётчики.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danonenka/PR-service/internal/domain"
+	"github.com/danonenka/PR-service/internal/usecase"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "pr_service"
+
+// Metrics реализует usecase.Metrics и отдаёт всё собранное через Handler.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpDuration *prometheus.HistogramVec
+	httpErrors   *prometheus.CounterVec
+
+	prsCreated        prometheus.Counter
+	reviewersAssigned *prometheus.CounterVec
+	reassignments     *prometheus.CounterVec
+	understaffed      *prometheus.CounterVec
+	prsMerged         prometheus.Counter
+}
+
+var _ usecase.Metrics = (*Metrics)(nil)
+
+// New регистрирует метрики в отдельном реестре. Если db не nil,
+// добавляются метрики пула соединений (go_sql_*).
+func New(db *sql.DB) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		httpErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_errors_total",
+			Help:      "Error responses by route and error code.",
+		}, []string{"route", "code"}),
+		prsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "prs_created_total",
+			Help:      "Pull requests created.",
+		}),
+		reviewersAssigned: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "reviewers_assigned_total",
+			Help:      "Reviewers assigned to pull requests by reason.",
+		}, []string{"reason"}),
+		reassignments: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "reassignments_total",
+			Help:      "Reviewer reassignments by reason and outcome.",
+		}, []string{"reason", "outcome"}),
+		understaffed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "prs_understaffed_total",
+			Help:      "Pull requests left with fewer than min_reviewers reviewers, by reason.",
+		}, []string{"reason"}),
+		prsMerged: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "prs_merged_total",
+			Help:      "Pull requests merged.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpDuration,
+		m.httpErrors,
+		m.prsCreated,
+		m.reviewersAssigned,
+		m.reassignments,
+		m.understaffed,
+		m.prsMerged,
+	)
+	if db != nil {
+		m.registry.MustRegister(collectors.NewDBStatsCollector(db, namespace))
+	}
+	return m
+}
+
+// Handler отдаёт метрики в текстовом формате Prometheus.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRequest учитывает HTTP-запрос; errorCode — код из тела ошибки,
+// пустой для успешных ответов.
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration, errorCode string) {
+	m.httpDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+	if errorCode != "" {
+		m.httpErrors.WithLabelValues(route, errorCode).Inc()
+	}
+}
+
+func (m *Metrics) PRCreated() {
+	m.prsCreated.Inc()
+}
+
+func (m *Metrics) ReviewersAssigned(reason domain.AssignmentReason, count int) {
+	if count > 0 {
+		m.reviewersAssigned.WithLabelValues(string(reason)).Add(float64(count))
+	}
+}
+
+func (m *Metrics) Reassignment(reason domain.AssignmentReason, outcome usecase.ReassignmentOutcome) {
+	m.reassignments.WithLabelValues(string(reason), strings.ToLower(string(outcome))).Inc()
+}
+
+func (m *Metrics) PRUnderstaffed(reason domain.AssignmentReason) {
+	m.understaffed.WithLabelValues(string(reason)).Inc()
+}
+
+func (m *Metrics) PRMerged() {
+	m.prsMerged.Inc()
+}
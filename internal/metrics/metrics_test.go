@@ -0,0 +1,82 @@
+package metrics_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/danonenka/PR-service/internal/delivery/http/middleware"
+	"github.com/danonenka/PR-service/internal/domain"
+	"github.com/danonenka/PR-service/internal/metrics"
+	"github.com/danonenka/PR-service/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	t.Helper()
+
+	server := httptest.NewServer(m.Handler())
+	defer server.Close()
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("scrape: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return string(body)
+}
+
+func TestDomainCounters(t *testing.T) {
+	m := metrics.New(nil)
+	m.PRCreated()
+	m.ReviewersAssigned(domain.AssignmentReasonAuto, 2)
+	m.ReviewersAssigned(domain.AssignmentReasonAuto, 0)
+	m.Reassignment(domain.AssignmentReasonDeactivation, usecase.OutcomeLeftShort)
+	m.PRUnderstaffed(domain.AssignmentReasonDeactivation)
+	m.PRMerged()
+
+	body := scrape(t, m)
+	for _, line := range []string{
+		`pr_service_prs_created_total 1`,
+		`pr_service_reviewers_assigned_total{reason="auto"} 2`,
+		`pr_service_reassignments_total{outcome="left_short",reason="deactivation"} 1`,
+		`pr_service_prs_understaffed_total{reason="deactivation"} 1`,
+		`pr_service_prs_merged_total 1`,
+	} {
+		if !strings.Contains(body, line) {
+			t.Errorf("missing %q", line)
+		}
+	}
+}
+
+func TestRequestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := metrics.New(nil)
+
+	engine := gin.New()
+	engine.Use(middleware.RequestMetrics(m), middleware.ErrorHandler())
+	engine.GET("/pullRequest/get", func(c *gin.Context) {
+		_ = c.Error(domain.ErrNoCandidate)
+	})
+
+	for _, path := range []string{"/pullRequest/get?pull_request_id=pr-1", "/missing"} {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	body := scrape(t, m)
+	for _, line := range []string{
+		`pr_service_http_request_duration_seconds_count{method="GET",route="/pullRequest/get",status="409"} 1`,
+		`pr_service_http_request_duration_seconds_count{method="GET",route="unmatched",status="404"} 1`,
+		`pr_service_http_errors_total{code="NO_CANDIDATE",route="/pullRequest/get"} 1`,
+	} {
+		if !strings.Contains(body, line) {
+			t.Errorf("missing %q", line)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+// Package migration применяет SQL-миграции из embed.FS. Версия схемы
+// хранится в таблице schema_migrations в том же формате, что у
+// golang-migrate, поэтому базы, размеченные утилитой migrate, подхватываются
+// без изменений.
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// ErrSchemaBehind — в базе применены не все миграции, известные бинарнику.
+var ErrSchemaBehind = errors.New("database schema is behind")
+
+// ErrDirty — предыдущая миграция golang-migrate упала посередине; схему
+// нужно починить вручную и выставить версию.
+var ErrDirty = errors.New("database schema is dirty")
+
+// advisoryLockID — ключ pg_advisory_lock, чтобы два процесса не применяли
+// миграции одновременно.
+const advisoryLockID = 72_315_001
+
+var fileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Load читает миграции из корня fsys и сортирует их по версии. У каждой
+// миграции должны быть оба файла: up и down.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("invalid migration version in %s", entry.Name())
+		}
+		script, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[uint(version)]
+		if !ok {
+			m = &Migration{Version: uint(version), Name: match[2]}
+			byVersion[uint(version)] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has different names: %s and %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(script)
+		} else {
+			m.Down = string(script)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+func NewMigrator(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Latest — версия последней миграции, которую знает бинарник.
+func (m *Migrator) Latest() uint {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+type Status struct {
+	Current uint
+	Dirty   bool
+	Latest  uint
+	// Pending — миграции новее Current, которые применит Up
+	Pending []Migration
+}
+
+// Status читает версию схемы, ничего не меняя в базе.
+func (m *Migrator) Status(ctx context.Context) (*Status, error) {
+	current, dirty, err := readVersion(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+	status := &Status{Current: current, Dirty: dirty, Latest: m.Latest(), Pending: make([]Migration, 0)}
+	for _, migration := range m.migrations {
+		if migration.Version > current {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+	return status, nil
+}
+
+// Check возвращает ErrSchemaBehind, если в базе применены не все
+// миграции бинарника, и ErrDirty, если схема осталась в промежуточном
+// состоянии. Схема новее бинарника допустима: так бывает при откате
+// релиза, и старый код работает с добавленными таблицами и колонками.
+func (m *Migrator) Check(ctx context.Context) error {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if status.Dirty {
+		return fmt.Errorf("%w at version %d", ErrDirty, status.Current)
+	}
+	if status.Current < status.Latest {
+		return fmt.Errorf("%w: version %d, binary expects %d", ErrSchemaBehind, status.Current, status.Latest)
+	}
+	return nil
+}
+
+// Up применяет все миграции новее текущей версии и возвращает их число.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	return m.Goto(ctx, m.Latest())
+}
+
+// Down откатывает steps последних применённых миграций.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	if steps <= 0 {
+		return 0, nil
+	}
+	current, _, err := readVersion(ctx, m.db)
+	if err != nil {
+		return 0, err
+	}
+	target := uint(0)
+	applied := m.appliedUpTo(current)
+	if steps < len(applied) {
+		target = applied[len(applied)-steps-1].Version
+	}
+	return m.Goto(ctx, target)
+}
+
+// Goto применяет или откатывает миграции, пока версия схемы не станет
+// равной version; 0 — откатить всё. Каждая миграция выполняется в своей
+// транзакции вместе с записью новой версии.
+func (m *Migrator) Goto(ctx context.Context, version uint) (int, error) {
+	if version != 0 && m.find(version) < 0 {
+		return 0, fmt.Errorf("unknown migration version %d", version)
+	}
+
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, advisoryLockID); err != nil {
+		return 0, err
+	}
+	defer func() {
+		_, _ = conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, advisoryLockID)
+	}()
+
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)`); err != nil {
+		return 0, err
+	}
+	current, dirty, err := readVersion(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return 0, fmt.Errorf("%w at version %d", ErrDirty, current)
+	}
+
+	steps := 0
+	for _, migration := range m.migrations {
+		if migration.Version <= current || migration.Version > version {
+			continue
+		}
+		if err := apply(ctx, conn, migration.Up, migration.Version); err != nil {
+			return steps, fmt.Errorf("migration %d_%s up: %w", migration.Version, migration.Name, err)
+		}
+		slog.InfoContext(ctx, "migration applied", "version", migration.Version, "name", migration.Name)
+		current = migration.Version
+		steps++
+	}
+	for current > version {
+		i := m.find(current)
+		if i < 0 {
+			return steps, fmt.Errorf("database version %d is not known to this binary", current)
+		}
+		previous := uint(0)
+		if i > 0 {
+			previous = m.migrations[i-1].Version
+		}
+		if err := apply(ctx, conn, m.migrations[i].Down, previous); err != nil {
+			return steps, fmt.Errorf("migration %d_%s down: %w", current, m.migrations[i].Name, err)
+		}
+		slog.InfoContext(ctx, "migration reverted", "version", current, "name", m.migrations[i].Name)
+		current = previous
+		steps++
+	}
+	return steps, nil
+}
+
+func (m *Migrator) find(version uint) int {
+	for i, migration := range m.migrations {
+		if migration.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *Migrator) appliedUpTo(version uint) []Migration {
+	applied := make([]Migration, 0)
+	for _, migration := range m.migrations {
+		if migration.Version <= version {
+			applied = append(applied, migration)
+		}
+	}
+	return applied
+}
+
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// readVersion возвращает 0, если таблицы schema_migrations ещё нет или
+// она пуста.
+func readVersion(ctx context.Context, db queryer) (uint, bool, error) {
+	var exists bool
+	if err := db.QueryRowContext(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return 0, false, err
+	}
+	if !exists {
+		return 0, false, nil
+	}
+
+	var version int64
+	var dirty bool
+	err := db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return uint(version), dirty, nil
+}
+
+// apply выполняет скрипт и записывает версию в одной транзакции: при
+// ошибке схема и версия остаются прежними. Версия 0 — пустая таблица,
+// как после полного отката в golang-migrate.
+func apply(ctx context.Context, conn *sql.Conn, script string, version uint) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations`); err != nil {
+		return err
+	}
+	if version > 0 {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)`, int64(version)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
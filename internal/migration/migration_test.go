@@ -0,0 +1,107 @@
+package migration_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/danonenka/PR-service/internal/migration"
+	"github.com/danonenka/PR-service/internal/testdb"
+	"github.com/danonenka/PR-service/migrations"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000002_b.up.sql":   {Data: []byte("b up")},
+		"000002_b.down.sql": {Data: []byte("b down")},
+		"000001_a.up.sql":   {Data: []byte("a up")},
+		"000001_a.down.sql": {Data: []byte("a down")},
+		"README.md":         {Data: []byte("ignored")},
+	}
+
+	loaded, err := migration.Load(fsys)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(loaded) != 2 || loaded[0].Version != 1 || loaded[1].Version != 2 {
+		t.Fatalf("unexpected migrations: %+v", loaded)
+	}
+	if loaded[0].Name != "a" || loaded[0].Up != "a up" || loaded[0].Down != "a down" {
+		t.Errorf("unexpected first migration: %+v", loaded[0])
+	}
+}
+
+func TestLoadRequiresDownFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000001_a.up.sql": {Data: []byte("a up")},
+	}
+	if _, err := migration.Load(fsys); err == nil {
+		t.Fatal("expected error for migration without down file")
+	}
+}
+
+func TestEmbeddedMigrationsLoad(t *testing.T) {
+	loaded, err := migration.Load(migrations.FS)
+	if err != nil {
+		t.Fatalf("load embedded migrations: %v", err)
+	}
+	for i, m := range loaded {
+		if m.Version != uint(i+1) {
+			t.Errorf("migration %d_%s: expected version %d", m.Version, m.Name, i+1)
+		}
+	}
+}
+
+// TestMigrator гоняет миграции на настоящем Postgres из TEST_DATABASE_URL;
+// база очищается до и после теста.
+func TestMigrator(t *testing.T) {
+	db := testdb.Open(t)
+	ctx := context.Background()
+	migrator, err := migration.NewMigrator(db, migrations.FS)
+	if err != nil {
+		t.Fatalf("new migrator: %v", err)
+	}
+	if _, err := migrator.Goto(ctx, 0); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = migrator.Goto(ctx, 0)
+	})
+
+	if err := migrator.Check(ctx); !errors.Is(err, migration.ErrSchemaBehind) {
+		t.Fatalf("expected ErrSchemaBehind on empty schema, got %v", err)
+	}
+
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if applied != int(migrator.Latest()) {
+		t.Errorf("expected %d migrations applied, got %d", migrator.Latest(), applied)
+	}
+	if err := migrator.Check(ctx); err != nil {
+		t.Fatalf("check after up: %v", err)
+	}
+	if applied, err := migrator.Up(ctx); err != nil || applied != 0 {
+		t.Errorf("repeated up: applied %d, err %v", applied, err)
+	}
+
+	if _, err := migrator.Down(ctx, 2); err != nil {
+		t.Fatalf("down: %v", err)
+	}
+	status, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if status.Current != migrator.Latest()-2 || len(status.Pending) != 2 {
+		t.Errorf("unexpected status after down: current %d, pending %d", status.Current, len(status.Pending))
+	}
+
+	if _, err := migrator.Goto(ctx, 3); err != nil {
+		t.Fatalf("goto 3: %v", err)
+	}
+	if status, _ := migrator.Status(ctx); status.Current != 3 {
+		t.Errorf("expected version 3, got %d", status.Current)
+	}
+}
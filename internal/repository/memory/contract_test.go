@@ -0,0 +1,25 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/danonenka/PR-service/internal/repository/memory"
+	"github.com/danonenka/PR-service/internal/repository/repositorytest"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Repositories {
+		store := memory.NewStore()
+		return repositorytest.Repositories{
+			Users:                memory.NewUserRepository(store),
+			Teams:                memory.NewTeamRepository(store),
+			PullRequests:         memory.NewPullRequestRepository(store),
+			Assignments:          memory.NewReviewerAssignmentRepository(store),
+			Events:               memory.NewPREventRepository(store),
+			WebhookSubscriptions: memory.NewWebhookSubscriptionRepository(store),
+			WebhookDeliveries:    memory.NewWebhookDeliveryRepository(store),
+			Identities:           memory.NewIdentityRepository(store),
+			TxManager:            memory.NewTxManager(store),
+		}
+	})
+}
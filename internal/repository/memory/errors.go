@@ -0,0 +1,23 @@
+package memory
+
+import (
+	"fmt"
+
+	"github.com/danonenka/PR-service/internal/domain"
+)
+
+// Ошибки строятся так же, как translateError в PostgreSQL-репозиториях:
+// нарушение уникальности — ErrConflict, ссылка на несуществующую запись
+// или отсутствие строки — ErrNotFound, нарушение CHECK — ErrInvalidArgument.
+
+func conflictError(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", domain.ErrConflict, fmt.Sprintf(format, args...))
+}
+
+func notFoundError(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", domain.ErrNotFound, fmt.Sprintf(format, args...))
+}
+
+func invalidError(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", domain.ErrInvalidArgument, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/danonenka/PR-service/internal/domain"
+)
+
+type IdentityRepository struct {
+	tables tables
+}
+
+func NewIdentityRepository(store *Store) *IdentityRepository {
+	return &IdentityRepository{tables: store}
+}
+
+// Upsert при перепривязке логина сохраняет исходное время создания, как
+// ON CONFLICT DO UPDATE в Postgres.
+func (r *IdentityRepository) Upsert(ctx context.Context, mapping *domain.IdentityMapping) error {
+	mapping.Login = strings.ToLower(mapping.Login)
+	return r.tables.write(func(d *data) error {
+		if _, ok := d.users[mapping.UserID]; !ok {
+			return notFoundError("user %s", mapping.UserID)
+		}
+		key := identityKey{provider: mapping.Provider, login: mapping.Login}
+		if existing, ok := d.identities[key]; ok {
+			mapping.CreatedAt = existing.CreatedAt
+		} else {
+			mapping.CreatedAt = d.now()
+		}
+		d.identities[key] = *mapping
+		return nil
+	})
+}
+
+func (r *IdentityRepository) GetUserID(ctx context.Context, provider, login string) (string, error) {
+	var userID string
+	var found bool
+	r.tables.read(func(d *data) {
+		var mapping domain.IdentityMapping
+		mapping, found = d.identities[identityKey{provider: provider, login: strings.ToLower(login)}]
+		userID = mapping.UserID
+	})
+	if !found {
+		return "", notFoundError("%s login %s", provider, login)
+	}
+	return userID, nil
+}
+
+func (r *IdentityRepository) GetByProvider(ctx context.Context, provider string) ([]*domain.IdentityMapping, error) {
+	mappings := make([]*domain.IdentityMapping, 0)
+	r.tables.read(func(d *data) {
+		for _, mapping := range d.identities {
+			if mapping.Provider == provider {
+				mappings = append(mappings, &mapping)
+			}
+		}
+	})
+	sort.Slice(mappings, func(i, j int) bool {
+		return mappings[i].Login < mappings[j].Login
+	})
+	return mappings, nil
+}
+
+type InboundDeliveryRepository struct {
+	tables tables
+}
+
+func NewInboundDeliveryRepository(store *Store) *InboundDeliveryRepository {
+	return &InboundDeliveryRepository{tables: store}
+}
+
+func (r *InboundDeliveryRepository) Claim(ctx context.Context, provider, deliveryID, event string) (bool, error) {
+	claimed := false
+	err := r.tables.write(func(d *data) error {
+		key := inboundKey{provider: provider, deliveryID: deliveryID}
+		if _, ok := d.inbound[key]; !ok {
+			d.inbound[key] = event
+			claimed = true
+		}
+		return nil
+	})
+	return claimed, err
+}
+
+func (r *InboundDeliveryRepository) Release(ctx context.Context, provider, deliveryID string) error {
+	return r.tables.write(func(d *data) error {
+		delete(d.inbound, inboundKey{provider: provider, deliveryID: deliveryID})
+		return nil
+	})
+}
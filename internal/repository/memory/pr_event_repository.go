@@ -0,0 +1,39 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/danonenka/PR-service/internal/domain"
+)
+
+// PREventRepository только добавляет записи; ссылок на PR и пользователей
+// не проверяет, как и таблица pr_events.
+type PREventRepository struct {
+	tables tables
+}
+
+func NewPREventRepository(store *Store) *PREventRepository {
+	return &PREventRepository{tables: store}
+}
+
+func (r *PREventRepository) Create(ctx context.Context, event *domain.PREvent) error {
+	return r.tables.write(func(d *data) error {
+		d.lastEventID++
+		event.ID = d.lastEventID
+		event.CreatedAt = d.now()
+		d.events = append(d.events, *event)
+		return nil
+	})
+}
+
+func (r *PREventRepository) GetByPRID(ctx context.Context, prID string) ([]*domain.PREvent, error) {
+	events := make([]*domain.PREvent, 0)
+	r.tables.read(func(d *data) {
+		for _, event := range d.events {
+			if event.PRID == prID {
+				events = append(events, &event)
+			}
+		}
+	})
+	return events, nil
+}
@@ -0,0 +1,236 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/danonenka/PR-service/internal/domain"
+)
+
+type PullRequestRepository struct {
+	tables tables
+}
+
+func NewPullRequestRepository(store *Store) *PullRequestRepository {
+	return &PullRequestRepository{tables: store}
+}
+
+// storedPR оставляет только колонки pull_requests: ревьюеры хранятся в
+// назначениях, RequiredReviewers и Reviews вычисляются при чтении.
+func storedPR(pr *domain.PullRequest) domain.PullRequest {
+	return domain.PullRequest{
+		ID:        pr.ID,
+		Title:     pr.Title,
+		AuthorID:  pr.AuthorID,
+		Status:    pr.Status,
+		CreatedAt: pr.CreatedAt,
+		MergedAt:  copyTime(pr.MergedAt),
+		ClosedAt:  copyTime(pr.ClosedAt),
+		UpdatedAt: pr.UpdatedAt,
+		Version:   pr.Version,
+	}
+}
+
+func loadPR(pr domain.PullRequest) *domain.PullRequest {
+	pr.MergedAt = copyTime(pr.MergedAt)
+	pr.ClosedAt = copyTime(pr.ClosedAt)
+	return &pr
+}
+
+// reviewerIDs возвращает ревьюеров PR в порядке назначения.
+func reviewerIDs(d *data, prID string) []string {
+	assignments := assignmentsOf(d, func(a domain.ReviewerAssignment) bool {
+		return a.PRID == prID
+	})
+	ids := make([]string, 0, len(assignments))
+	for _, assignment := range assignments {
+		ids = append(ids, assignment.ReviewerID)
+	}
+	return ids
+}
+
+func checkPR(d *data, pr *domain.PullRequest) error {
+	if !pr.Status.IsValid() {
+		return invalidError("PR %s: invalid status %q", pr.ID, pr.Status)
+	}
+	if _, ok := d.users[pr.AuthorID]; !ok {
+		return notFoundError("author %s", pr.AuthorID)
+	}
+	return nil
+}
+
+func (r *PullRequestRepository) Create(ctx context.Context, pr *domain.PullRequest) error {
+	return r.tables.write(func(d *data) error {
+		if _, ok := d.pullRequests[pr.ID]; ok {
+			return conflictError("PR %s already exists", pr.ID)
+		}
+		if err := checkPR(d, pr); err != nil {
+			return err
+		}
+		pr.Version = 1
+		d.pullRequests[pr.ID] = storedPR(pr)
+		return nil
+	})
+}
+
+func (r *PullRequestRepository) GetByID(ctx context.Context, id string) (*domain.PullRequest, error) {
+	var pr *domain.PullRequest
+	r.tables.read(func(d *data) {
+		if stored, ok := d.pullRequests[id]; ok {
+			pr = loadPR(stored)
+		}
+	})
+	if pr == nil {
+		return nil, notFoundError("PR %s", id)
+	}
+	return pr, nil
+}
+
+// GetByIDForUpdate не отличается от GetByID: транзакции и так
+// выполняются по одной.
+func (r *PullRequestRepository) GetByIDForUpdate(ctx context.Context, id string) (*domain.PullRequest, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *PullRequestRepository) GetByAuthorID(ctx context.Context, authorID string) ([]*domain.PullRequest, error) {
+	return r.List(ctx, domain.PRListFilter{AuthorID: authorID})
+}
+
+func (r *PullRequestRepository) GetByReviewerID(ctx context.Context, reviewerID string) ([]*domain.PullRequest, error) {
+	return r.List(ctx, domain.PRListFilter{ReviewerID: reviewerID})
+}
+
+func (r *PullRequestRepository) GetOpenByReviewerIDs(ctx context.Context, reviewerIDs []string) ([]*domain.PullRequest, error) {
+	wanted := make(map[string]bool, len(reviewerIDs))
+	for _, id := range reviewerIDs {
+		wanted[id] = true
+	}
+	prs := make([]*domain.PullRequest, 0)
+	r.tables.read(func(d *data) {
+		matched := make(map[string]bool)
+		for key := range d.assignments {
+			if wanted[key.reviewerID] {
+				matched[key.prID] = true
+			}
+		}
+		for prID := range matched {
+			if pr := d.pullRequests[prID]; pr.Status == domain.PRStatusOpen {
+				prs = append(prs, withReviewers(d, pr))
+			}
+		}
+	})
+	sortPRs(prs, domain.PRSortCreatedAt, false)
+	return prs, nil
+}
+
+func withReviewers(d *data, stored domain.PullRequest) *domain.PullRequest {
+	pr := loadPR(stored)
+	pr.ReviewerIDs = reviewerIDs(d, pr.ID)
+	return pr
+}
+
+func sortValue(pr *domain.PullRequest, field domain.PRSortField) time.Time {
+	if field == domain.PRSortUpdatedAt {
+		return pr.UpdatedAt
+	}
+	return pr.CreatedAt
+}
+
+// comparePR сравнивает PR по (поле сортировки, id), как ORDER BY в Postgres.
+func comparePR(value time.Time, id string, cursor time.Time, cursorID string) int {
+	if c := value.Compare(cursor); c != 0 {
+		return c
+	}
+	return strings.Compare(id, cursorID)
+}
+
+func sortPRs(prs []*domain.PullRequest, field domain.PRSortField, descending bool) {
+	sort.Slice(prs, func(i, j int) bool {
+		c := comparePR(sortValue(prs[i], field), prs[i].ID, sortValue(prs[j], field), prs[j].ID)
+		if descending {
+			return c > 0
+		}
+		return c < 0
+	})
+}
+
+func (r *PullRequestRepository) List(ctx context.Context, filter domain.PRListFilter) ([]*domain.PullRequest, error) {
+	prs := make([]*domain.PullRequest, 0)
+	r.tables.read(func(d *data) {
+		for _, stored := range d.pullRequests {
+			if matchesFilter(d, &stored, filter) {
+				prs = append(prs, withReviewers(d, stored))
+			}
+		}
+	})
+
+	sortPRs(prs, filter.SortBy, filter.Descending)
+	if filter.After != nil {
+		start := sort.Search(len(prs), func(i int) bool {
+			c := comparePR(sortValue(prs[i], filter.SortBy), prs[i].ID, filter.After.Value, filter.After.ID)
+			if filter.Descending {
+				return c < 0
+			}
+			return c > 0
+		})
+		prs = prs[start:]
+	}
+	if filter.Limit > 0 && len(prs) > filter.Limit {
+		prs = prs[:filter.Limit]
+	}
+	return prs, nil
+}
+
+func matchesFilter(d *data, pr *domain.PullRequest, filter domain.PRListFilter) bool {
+	if filter.AuthorID != "" && pr.AuthorID != filter.AuthorID {
+		return false
+	}
+	if filter.TeamID != "" && d.users[pr.AuthorID].TeamID != filter.TeamID {
+		return false
+	}
+	if filter.ReviewerID != "" {
+		if _, ok := d.assignments[assignmentKey{prID: pr.ID, reviewerID: filter.ReviewerID}]; !ok {
+			return false
+		}
+	}
+	if filter.Status != "" && pr.Status != filter.Status {
+		return false
+	}
+	if filter.CreatedFrom != nil && pr.CreatedAt.Before(*filter.CreatedFrom) {
+		return false
+	}
+	if filter.CreatedTo != nil && !pr.CreatedAt.Before(*filter.CreatedTo) {
+		return false
+	}
+	return true
+}
+
+func (r *PullRequestRepository) Update(ctx context.Context, pr *domain.PullRequest) error {
+	return r.tables.write(func(d *data) error {
+		stored, ok := d.pullRequests[pr.ID]
+		if !ok || stored.Version != pr.Version {
+			return domain.ErrConcurrentModification
+		}
+		if err := checkPR(d, pr); err != nil {
+			return err
+		}
+		updated := storedPR(pr)
+		updated.Version++
+		d.pullRequests[pr.ID] = updated
+		pr.Version = updated.Version
+		return nil
+	})
+}
+
+func (r *PullRequestRepository) GetAll(ctx context.Context) ([]*domain.PullRequest, error) {
+	prs := make([]*domain.PullRequest, 0)
+	r.tables.read(func(d *data) {
+		for _, stored := range d.pullRequests {
+			prs = append(prs, loadPR(stored))
+		}
+	})
+	sortPRs(prs, domain.PRSortCreatedAt, false)
+	return prs, nil
+}
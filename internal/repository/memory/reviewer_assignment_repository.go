@@ -0,0 +1,142 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/danonenka/PR-service/internal/domain"
+)
+
+type ReviewerAssignmentRepository struct {
+	tables tables
+}
+
+func NewReviewerAssignmentRepository(store *Store) *ReviewerAssignmentRepository {
+	return &ReviewerAssignmentRepository{tables: store}
+}
+
+// assignmentsOf возвращает подходящие назначения в порядке
+// (assigned_at, reviewer_id).
+func assignmentsOf(d *data, match func(a domain.ReviewerAssignment) bool) []*domain.ReviewerAssignment {
+	assignments := make([]*domain.ReviewerAssignment, 0)
+	for _, assignment := range d.assignments {
+		if match(assignment) {
+			assignment.ReviewedAt = copyTime(assignment.ReviewedAt)
+			assignments = append(assignments, &assignment)
+		}
+	}
+	sort.Slice(assignments, func(i, j int) bool {
+		if c := assignments[i].AssignedAt.Compare(assignments[j].AssignedAt); c != 0 {
+			return c < 0
+		}
+		if assignments[i].ReviewerID != assignments[j].ReviewerID {
+			return assignments[i].ReviewerID < assignments[j].ReviewerID
+		}
+		return assignments[i].PRID < assignments[j].PRID
+	})
+	return assignments
+}
+
+// validState повторяет chk_reviewer_assignments_state.
+func validState(state domain.ReviewState) bool {
+	return state == domain.ReviewStatePending || state.IsVerdict()
+}
+
+// Create сохраняет назначение в состоянии PENDING, если State не задан.
+func (r *ReviewerAssignmentRepository) Create(ctx context.Context, assignment *domain.ReviewerAssignment) error {
+	if assignment.State == "" {
+		assignment.State = domain.ReviewStatePending
+	}
+	return r.tables.write(func(d *data) error {
+		key := assignmentKey{prID: assignment.PRID, reviewerID: assignment.ReviewerID}
+		if _, ok := d.assignments[key]; ok {
+			return conflictError("reviewer %s is already assigned to PR %s", assignment.ReviewerID, assignment.PRID)
+		}
+		if _, ok := d.pullRequests[assignment.PRID]; !ok {
+			return notFoundError("PR %s", assignment.PRID)
+		}
+		if _, ok := d.users[assignment.ReviewerID]; !ok {
+			return notFoundError("reviewer %s", assignment.ReviewerID)
+		}
+		if !validState(assignment.State) {
+			return invalidError("invalid review state %q", assignment.State)
+		}
+
+		assignment.AssignedAt = d.now()
+		stored := *assignment
+		stored.ReviewedAt = copyTime(assignment.ReviewedAt)
+		d.assignments[key] = stored
+		return nil
+	})
+}
+
+func (r *ReviewerAssignmentRepository) Delete(ctx context.Context, prID string, reviewerID string) error {
+	return r.tables.write(func(d *data) error {
+		delete(d.assignments, assignmentKey{prID: prID, reviewerID: reviewerID})
+		return nil
+	})
+}
+
+func (r *ReviewerAssignmentRepository) GetByPRID(ctx context.Context, prID string) ([]*domain.ReviewerAssignment, error) {
+	var assignments []*domain.ReviewerAssignment
+	r.tables.read(func(d *data) {
+		assignments = assignmentsOf(d, func(a domain.ReviewerAssignment) bool {
+			return a.PRID == prID
+		})
+	})
+	return assignments, nil
+}
+
+func (r *ReviewerAssignmentRepository) GetByReviewerID(ctx context.Context, reviewerID string) ([]*domain.ReviewerAssignment, error) {
+	var assignments []*domain.ReviewerAssignment
+	r.tables.read(func(d *data) {
+		assignments = assignmentsOf(d, func(a domain.ReviewerAssignment) bool {
+			return a.ReviewerID == reviewerID
+		})
+	})
+	return assignments, nil
+}
+
+func (r *ReviewerAssignmentRepository) DeleteByPRID(ctx context.Context, prID string) error {
+	return r.tables.write(func(d *data) error {
+		for key := range d.assignments {
+			if key.prID == prID {
+				delete(d.assignments, key)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *ReviewerAssignmentRepository) UpdateState(ctx context.Context, assignment *domain.ReviewerAssignment) error {
+	return r.tables.write(func(d *data) error {
+		key := assignmentKey{prID: assignment.PRID, reviewerID: assignment.ReviewerID}
+		stored, ok := d.assignments[key]
+		if !ok {
+			return domain.ErrNotFound
+		}
+		if !validState(assignment.State) {
+			return invalidError("invalid review state %q", assignment.State)
+		}
+		stored.State = assignment.State
+		stored.ReviewedAt = copyTime(assignment.ReviewedAt)
+		d.assignments[key] = stored
+		return nil
+	})
+}
+
+func (r *ReviewerAssignmentRepository) CountOpenByReviewerIDs(ctx context.Context, reviewerIDs []string) (map[string]int, error) {
+	wanted := make(map[string]bool, len(reviewerIDs))
+	for _, id := range reviewerIDs {
+		wanted[id] = true
+	}
+	counts := make(map[string]int)
+	r.tables.read(func(d *data) {
+		for key := range d.assignments {
+			if wanted[key.reviewerID] && d.pullRequests[key.prID].Status == domain.PRStatusOpen {
+				counts[key.reviewerID]++
+			}
+		}
+	})
+	return counts, nil
+}
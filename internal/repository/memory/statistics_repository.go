@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/danonenka/PR-service/internal/domain"
+)
+
+type StatisticsRepository struct {
+	tables tables
+}
+
+func NewStatisticsRepository(store *Store) *StatisticsRepository {
+	return &StatisticsRepository{tables: store}
+}
+
+// matchesStats проверяет условия фильтра, кроме команды: её колонка
+// зависит от отчёта.
+func matchesStats(pr domain.PullRequest, filter domain.StatsFilter) bool {
+	if filter.Status != "" && pr.Status != filter.Status {
+		return false
+	}
+	if filter.From != nil && pr.CreatedAt.Before(*filter.From) {
+		return false
+	}
+	if filter.To != nil && !pr.CreatedAt.Before(*filter.To) {
+		return false
+	}
+	return true
+}
+
+func (r *StatisticsRepository) ReviewerStats(ctx context.Context, filter domain.StatsFilter) ([]*domain.ReviewerStats, error) {
+	byReviewer := make(map[string]*domain.ReviewerStats)
+	r.tables.read(func(d *data) {
+		for key := range d.assignments {
+			pr, ok := d.pullRequests[key.prID]
+			reviewer, known := d.users[key.reviewerID]
+			if !ok || !known || !matchesStats(pr, filter) {
+				continue
+			}
+			if filter.TeamID != "" && reviewer.TeamID != filter.TeamID {
+				continue
+			}
+
+			stat, ok := byReviewer[reviewer.ID]
+			if !ok {
+				stat = &domain.ReviewerStats{UserID: reviewer.ID, UserName: reviewer.Name}
+				byReviewer[reviewer.ID] = stat
+			}
+			stat.Assignments++
+			switch pr.Status {
+			case domain.PRStatusOpen:
+				stat.OpenAssignments++
+			case domain.PRStatusMerged:
+				stat.MergedAssignments++
+			}
+		}
+	})
+
+	stats := make([]*domain.ReviewerStats, 0, len(byReviewer))
+	for _, stat := range byReviewer {
+		stats = append(stats, stat)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		a, b := stats[i], stats[j]
+		if a.OpenAssignments != b.OpenAssignments {
+			return a.OpenAssignments > b.OpenAssignments
+		}
+		if a.Assignments != b.Assignments {
+			return a.Assignments > b.Assignments
+		}
+		return a.UserID < b.UserID
+	})
+	return stats, nil
+}
+
+func (r *StatisticsRepository) PullRequestStats(ctx context.Context, filter domain.StatsFilter) ([]*domain.PullRequestStats, error) {
+	prs := make([]*domain.PullRequest, 0)
+	counts := make(map[string]int)
+	r.tables.read(func(d *data) {
+		for _, pr := range d.pullRequests {
+			author, ok := d.users[pr.AuthorID]
+			if !ok || !matchesStats(pr, filter) {
+				continue
+			}
+			if filter.TeamID != "" && author.TeamID != filter.TeamID {
+				continue
+			}
+			prs = append(prs, loadPR(pr))
+		}
+		for key := range d.assignments {
+			counts[key.prID]++
+		}
+	})
+
+	sortPRs(prs, domain.PRSortCreatedAt, false)
+	stats := make([]*domain.PullRequestStats, 0, len(prs))
+	for _, pr := range prs {
+		stats = append(stats, &domain.PullRequestStats{
+			PRID:        pr.ID,
+			PRTitle:     pr.Title,
+			AuthorID:    pr.AuthorID,
+			Status:      pr.Status,
+			Assignments: counts[pr.ID],
+		})
+	}
+	return stats, nil
+}
@@ -0,0 +1,146 @@
+// Package memory хранит данные сервиса в памяти процесса. Репозитории
+// повторяют семантику PostgreSQL-реализации, включая ошибки уникальности
+// и внешних ключей, и нужны для тестов и локального запуска без базы
+// (STORAGE=memory). Данные теряются при остановке процесса.
+package memory
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/danonenka/PR-service/internal/domain"
+)
+
+// Store — общее хранилище всех репозиториев пакета.
+type Store struct {
+	// writeMu сериализует транзакции и одиночные изменения. Транзакция
+	// работает с копией данных и подменяет ими хранилище при фиксации,
+	// поэтому читатели видят только зафиксированное состояние.
+	writeMu sync.Mutex
+	mu      sync.RWMutex
+	data    *data
+}
+
+func NewStore() *Store {
+	return &Store{data: newData()}
+}
+
+type assignmentKey struct {
+	prID       string
+	reviewerID string
+}
+
+type identityKey struct {
+	provider string
+	login    string
+}
+
+type inboundKey struct {
+	provider   string
+	deliveryID string
+}
+
+// data — аналог таблиц схемы. Значения хранятся копиями, чтобы
+// вызывающий не мог изменить их в обход репозитория.
+type data struct {
+	teams         map[string]domain.Team
+	users         map[string]domain.User
+	pullRequests  map[string]domain.PullRequest
+	assignments   map[assignmentKey]domain.ReviewerAssignment
+	events        []domain.PREvent
+	subscriptions map[string]domain.WebhookSubscription
+	deliveries    map[int64]domain.WebhookDelivery
+	identities    map[identityKey]domain.IdentityMapping
+	// inbound — принятые входящие вебхуки: ключ -> тип события
+	inbound map[inboundKey]string
+
+	lastEventID    int64
+	lastDeliveryID int64
+
+	// txTime — время начала транзакции: как now() в Postgres, оно одно
+	// на все записи транзакции
+	txTime time.Time
+}
+
+func newData() *data {
+	return &data{
+		teams:         make(map[string]domain.Team),
+		users:         make(map[string]domain.User),
+		pullRequests:  make(map[string]domain.PullRequest),
+		assignments:   make(map[assignmentKey]domain.ReviewerAssignment),
+		events:        make([]domain.PREvent, 0),
+		subscriptions: make(map[string]domain.WebhookSubscription),
+		deliveries:    make(map[int64]domain.WebhookDelivery),
+		identities:    make(map[identityKey]domain.IdentityMapping),
+		inbound:       make(map[inboundKey]string),
+	}
+}
+
+func (d *data) clone() *data {
+	return &data{
+		teams:          maps.Clone(d.teams),
+		users:          maps.Clone(d.users),
+		pullRequests:   maps.Clone(d.pullRequests),
+		assignments:    maps.Clone(d.assignments),
+		events:         slices.Clone(d.events),
+		subscriptions:  maps.Clone(d.subscriptions),
+		deliveries:     maps.Clone(d.deliveries),
+		identities:     maps.Clone(d.identities),
+		inbound:        maps.Clone(d.inbound),
+		lastEventID:    d.lastEventID,
+		lastDeliveryID: d.lastDeliveryID,
+	}
+}
+
+func (d *data) now() time.Time {
+	if !d.txTime.IsZero() {
+		return d.txTime
+	}
+	return time.Now().UTC()
+}
+
+// tables — доступ репозитория к данным: к хранилищу под блокировкой или к
+// копии внутри транзакции. write не должна менять данные, если
+// возвращает ошибку: все проверки выполняются до изменений.
+type tables interface {
+	read(fn func(d *data))
+	write(fn func(d *data) error) error
+}
+
+func (s *Store) read(fn func(d *data)) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	fn(s.data)
+}
+
+func (s *Store) write(fn func(d *data) error) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fn(s.data)
+}
+
+// txTables — данные транзакции; ими пользуется только её горутина, а
+// остальные изменения ждут фиксации на writeMu.
+type txTables struct {
+	data *data
+}
+
+func (t txTables) read(fn func(d *data)) {
+	fn(t.data)
+}
+
+func (t txTables) write(fn func(d *data) error) error {
+	return fn(t.data)
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	copied := *t
+	return &copied
+}
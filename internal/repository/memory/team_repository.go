@@ -0,0 +1,146 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/danonenka/PR-service/internal/domain"
+)
+
+type TeamRepository struct {
+	tables tables
+}
+
+func NewTeamRepository(store *Store) *TeamRepository {
+	return &TeamRepository{tables: store}
+}
+
+// checkTeam повторяет ограничения chk_teams_reviewer_limits и
+// chk_teams_required_approvals, а также уникальность имени.
+func checkTeam(d *data, team *domain.Team) error {
+	if !domain.ValidReviewerLimits(team.MinReviewers, team.MaxReviewers) {
+		return invalidError("team %s: invalid reviewer limits %d..%d", team.Name, team.MinReviewers, team.MaxReviewers)
+	}
+	if !domain.ValidRequiredApprovals(team.RequiredApprovals, team.MaxReviewers) {
+		return invalidError("team %s: invalid required approvals %d", team.Name, team.RequiredApprovals)
+	}
+	for _, other := range d.teams {
+		if other.ID != team.ID && other.Name == team.Name {
+			return conflictError("team name %s already exists", team.Name)
+		}
+	}
+	return nil
+}
+
+func (r *TeamRepository) Create(ctx context.Context, team *domain.Team) error {
+	return r.tables.write(func(d *data) error {
+		if _, ok := d.teams[team.ID]; ok {
+			return conflictError("team %s already exists", team.ID)
+		}
+		if err := checkTeam(d, team); err != nil {
+			return err
+		}
+		d.teams[team.ID] = *team
+		return nil
+	})
+}
+
+func (r *TeamRepository) GetByID(ctx context.Context, id string) (*domain.Team, error) {
+	var team *domain.Team
+	r.tables.read(func(d *data) {
+		if stored, ok := d.teams[id]; ok {
+			team = &stored
+		}
+	})
+	if team == nil {
+		return nil, notFoundError("team %s", id)
+	}
+	return team, nil
+}
+
+func (r *TeamRepository) GetByName(ctx context.Context, name string) (*domain.Team, error) {
+	var team *domain.Team
+	r.tables.read(func(d *data) {
+		for _, stored := range d.teams {
+			if stored.Name == name {
+				team = &stored
+				return
+			}
+		}
+	})
+	if team == nil {
+		return nil, notFoundError("team %s", name)
+	}
+	return team, nil
+}
+
+func (r *TeamRepository) GetAll(ctx context.Context) ([]*domain.Team, error) {
+	teams := make([]*domain.Team, 0)
+	r.tables.read(func(d *data) {
+		for _, team := range d.teams {
+			teams = append(teams, &team)
+		}
+	})
+	sort.Slice(teams, func(i, j int) bool {
+		return teams[i].ID < teams[j].ID
+	})
+	return teams, nil
+}
+
+func (r *TeamRepository) Update(ctx context.Context, team *domain.Team) error {
+	return r.tables.write(func(d *data) error {
+		if _, ok := d.teams[team.ID]; !ok {
+			return nil
+		}
+		if err := checkTeam(d, team); err != nil {
+			return err
+		}
+		d.teams[team.ID] = *team
+		return nil
+	})
+}
+
+// Delete удаляет команду каскадно, как ON DELETE CASCADE в схеме:
+// участников, их PR и назначения, привязки логинов и подписки команды
+// вместе с их доставками. Журнал событий PR не трогается.
+func (r *TeamRepository) Delete(ctx context.Context, id string) error {
+	return r.tables.write(func(d *data) error {
+		if _, ok := d.teams[id]; !ok {
+			return nil
+		}
+		delete(d.teams, id)
+
+		for userID, user := range d.users {
+			if user.TeamID == id {
+				deleteUser(d, userID)
+			}
+		}
+		for subscriptionID, subscription := range d.subscriptions {
+			if subscription.TeamID == id {
+				deleteSubscription(d, subscriptionID)
+			}
+		}
+		return nil
+	})
+}
+
+func deleteUser(d *data, userID string) {
+	delete(d.users, userID)
+	for prID, pr := range d.pullRequests {
+		if pr.AuthorID == userID {
+			delete(d.pullRequests, prID)
+		}
+	}
+	for key := range d.assignments {
+		if key.reviewerID == userID {
+			delete(d.assignments, key)
+		} else if _, ok := d.pullRequests[key.prID]; !ok {
+			delete(d.assignments, key)
+		}
+	}
+	for key, mapping := range d.identities {
+		if mapping.UserID == userID {
+			delete(d.identities, key)
+		}
+	}
+}
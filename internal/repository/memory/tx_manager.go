@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/danonenka/PR-service/internal/domain"
+)
+
+type TxManager struct {
+	store *Store
+}
+
+func NewTxManager(store *Store) *TxManager {
+	return &TxManager{store: store}
+}
+
+// WithinTx выполняет fn над копией данных и подменяет ими хранилище, если
+// fn завершилась без ошибки. Транзакции выполняются по одной, что даёт
+// уровень изоляции SERIALIZABLE; блокировки GetByIDForUpdate не нужны.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(uow domain.UnitOfWork) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.store.writeMu.Lock()
+	defer m.store.writeMu.Unlock()
+
+	m.store.mu.RLock()
+	snapshot := m.store.data.clone()
+	m.store.mu.RUnlock()
+	snapshot.txTime = snapshot.now()
+
+	// При панике копия просто отбрасывается, а writeMu освобождает defer
+	if err := fn(newUnitOfWork(txTables{data: snapshot})); err != nil {
+		return err
+	}
+
+	snapshot.txTime = time.Time{}
+	m.store.mu.Lock()
+	m.store.data = snapshot
+	m.store.mu.Unlock()
+	return nil
+}
+
+type unitOfWork struct {
+	users        *UserRepository
+	teams        *TeamRepository
+	pullRequests *PullRequestRepository
+	assignments  *ReviewerAssignmentRepository
+	events       *PREventRepository
+	webhooks     *WebhookSubscriptionRepository
+	deliveries   *WebhookDeliveryRepository
+}
+
+func newUnitOfWork(t tables) *unitOfWork {
+	return &unitOfWork{
+		users:        &UserRepository{tables: t},
+		teams:        &TeamRepository{tables: t},
+		pullRequests: &PullRequestRepository{tables: t},
+		assignments:  &ReviewerAssignmentRepository{tables: t},
+		events:       &PREventRepository{tables: t},
+		webhooks:     &WebhookSubscriptionRepository{tables: t},
+		deliveries:   &WebhookDeliveryRepository{tables: t},
+	}
+}
+
+func (u *unitOfWork) Users() domain.UserRepository {
+	return u.users
+}
+
+func (u *unitOfWork) Teams() domain.TeamRepository {
+	return u.teams
+}
+
+func (u *unitOfWork) PullRequests() domain.PullRequestRepository {
+	return u.pullRequests
+}
+
+func (u *unitOfWork) Assignments() domain.ReviewerAssignmentRepository {
+	return u.assignments
+}
+
+func (u *unitOfWork) Events() domain.PREventRepository {
+	return u.events
+}
+
+func (u *unitOfWork) WebhookSubscriptions() domain.WebhookSubscriptionRepository {
+	return u.webhooks
+}
+
+func (u *unitOfWork) WebhookDeliveries() domain.WebhookDeliveryRepository {
+	return u.deliveries
+}
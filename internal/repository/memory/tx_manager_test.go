@@ -0,0 +1,64 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/danonenka/PR-service/internal/domain"
+	"github.com/danonenka/PR-service/internal/repository/memory"
+)
+
+func TestWithinTxRollsBack(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	teams := memory.NewTeamRepository(store)
+	txManager := memory.NewTxManager(store)
+
+	errAbort := errors.New("abort")
+	err := txManager.WithinTx(ctx, func(uow domain.UnitOfWork) error {
+		if err := uow.Teams().Create(ctx, &domain.Team{ID: "t1", Name: "backend", MaxReviewers: 2}); err != nil {
+			return err
+		}
+		// Внутри транзакции изменения видны, снаружи — нет
+		if _, err := uow.Teams().GetByID(ctx, "t1"); err != nil {
+			t.Errorf("uncommitted team must be visible in tx: %v", err)
+		}
+		if _, err := teams.GetByID(ctx, "t1"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("uncommitted team must not be visible outside tx: %v", err)
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("expected fn error, got %v", err)
+	}
+	if _, err := teams.GetByID(ctx, "t1"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("team must be rolled back, got %v", err)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("panic must be propagated")
+			}
+		}()
+		_ = txManager.WithinTx(ctx, func(uow domain.UnitOfWork) error {
+			_ = uow.Teams().Create(ctx, &domain.Team{ID: "t2", Name: "frontend", MaxReviewers: 2})
+			panic("boom")
+		})
+	}()
+	if _, err := teams.GetByID(ctx, "t2"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("team must be rolled back after panic, got %v", err)
+	}
+
+	// После паники хранилище не остаётся заблокированным
+	err = txManager.WithinTx(ctx, func(uow domain.UnitOfWork) error {
+		return uow.Teams().Create(ctx, &domain.Team{ID: "t3", Name: "mobile", MaxReviewers: 2})
+	})
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if _, err := teams.GetByName(ctx, "mobile"); err != nil {
+		t.Errorf("committed team not found: %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/danonenka/PR-service/internal/domain"
+)
+
+type UserRepository struct {
+	tables tables
+}
+
+func NewUserRepository(store *Store) *UserRepository {
+	return &UserRepository{tables: store}
+}
+
+func sortUsers(users []*domain.User) []*domain.User {
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].ID < users[j].ID
+	})
+	return users
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
+	return r.tables.write(func(d *data) error {
+		if _, ok := d.users[user.ID]; ok {
+			return conflictError("user %s already exists", user.ID)
+		}
+		if _, ok := d.teams[user.TeamID]; !ok {
+			return notFoundError("team %s", user.TeamID)
+		}
+		d.users[user.ID] = *user
+		return nil
+	})
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	var user *domain.User
+	r.tables.read(func(d *data) {
+		if stored, ok := d.users[id]; ok {
+			user = &stored
+		}
+	})
+	if user == nil {
+		return nil, notFoundError("user %s", id)
+	}
+	return user, nil
+}
+
+func (r *UserRepository) GetByTeamID(ctx context.Context, teamID string) ([]*domain.User, error) {
+	return r.filter(func(user domain.User) bool {
+		return user.TeamID == teamID
+	}), nil
+}
+
+func (r *UserRepository) GetActiveByTeamID(ctx context.Context, teamID string) ([]*domain.User, error) {
+	return r.filter(func(user domain.User) bool {
+		return user.TeamID == teamID && user.IsActive
+	}), nil
+}
+
+func (r *UserRepository) filter(match func(user domain.User) bool) []*domain.User {
+	users := make([]*domain.User, 0)
+	r.tables.read(func(d *data) {
+		for _, user := range d.users {
+			if match(user) {
+				users = append(users, &user)
+			}
+		}
+	})
+	return sortUsers(users)
+}
+
+// Update, как UPDATE в Postgres, молча ничего не делает для
+// несуществующего пользователя.
+func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
+	return r.tables.write(func(d *data) error {
+		if _, ok := d.users[user.ID]; !ok {
+			return nil
+		}
+		if _, ok := d.teams[user.TeamID]; !ok {
+			return notFoundError("team %s", user.TeamID)
+		}
+		d.users[user.ID] = *user
+		return nil
+	})
+}
+
+func (r *UserRepository) DeactivateUsers(ctx context.Context, teamID string, userIDs []string) error {
+	return r.tables.write(func(d *data) error {
+		for _, id := range userIDs {
+			if user, ok := d.users[id]; ok && user.TeamID == teamID {
+				user.IsActive = false
+				d.users[id] = user
+			}
+		}
+		return nil
+	})
+}
+
+func (r *UserRepository) GetByIDs(ctx context.Context, ids []string) ([]*domain.User, error) {
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	return r.filter(func(user domain.User) bool {
+		return wanted[user.ID]
+	}), nil
+}
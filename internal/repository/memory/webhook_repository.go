@@ -0,0 +1,201 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/danonenka/PR-service/internal/domain"
+)
+
+type WebhookSubscriptionRepository struct {
+	tables tables
+}
+
+func NewWebhookSubscriptionRepository(store *Store) *WebhookSubscriptionRepository {
+	return &WebhookSubscriptionRepository{tables: store}
+}
+
+func loadSubscription(subscription domain.WebhookSubscription) *domain.WebhookSubscription {
+	subscription.EventTypes = slices.Clone(subscription.EventTypes)
+	if subscription.EventTypes == nil {
+		subscription.EventTypes = make([]domain.WebhookEventType, 0)
+	}
+	return &subscription
+}
+
+func (r *WebhookSubscriptionRepository) Create(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	return r.tables.write(func(d *data) error {
+		if _, ok := d.subscriptions[subscription.ID]; ok {
+			return conflictError("webhook %s already exists", subscription.ID)
+		}
+		if subscription.TeamID != "" {
+			if _, ok := d.teams[subscription.TeamID]; !ok {
+				return notFoundError("team %s", subscription.TeamID)
+			}
+		}
+		subscription.CreatedAt = d.now()
+		d.subscriptions[subscription.ID] = *loadSubscription(*subscription)
+		return nil
+	})
+}
+
+func (r *WebhookSubscriptionRepository) GetByID(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	var subscription *domain.WebhookSubscription
+	r.tables.read(func(d *data) {
+		if stored, ok := d.subscriptions[id]; ok {
+			subscription = loadSubscription(stored)
+		}
+	})
+	if subscription == nil {
+		return nil, notFoundError("webhook %s", id)
+	}
+	return subscription, nil
+}
+
+func (r *WebhookSubscriptionRepository) GetAll(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	subscriptions := r.filter(func(*domain.WebhookSubscription) bool { return true })
+	sort.Slice(subscriptions, func(i, j int) bool {
+		if c := subscriptions[i].CreatedAt.Compare(subscriptions[j].CreatedAt); c != 0 {
+			return c < 0
+		}
+		return subscriptions[i].ID < subscriptions[j].ID
+	})
+	return subscriptions, nil
+}
+
+func (r *WebhookSubscriptionRepository) Delete(ctx context.Context, id string) error {
+	return r.tables.write(func(d *data) error {
+		if _, ok := d.subscriptions[id]; !ok {
+			return domain.ErrNotFound
+		}
+		deleteSubscription(d, id)
+		return nil
+	})
+}
+
+// deleteSubscription удаляет подписку вместе с её доставками.
+func deleteSubscription(d *data, id string) {
+	delete(d.subscriptions, id)
+	for deliveryID, delivery := range d.deliveries {
+		if delivery.SubscriptionID == id {
+			delete(d.deliveries, deliveryID)
+		}
+	}
+}
+
+func (r *WebhookSubscriptionRepository) GetMatching(ctx context.Context, teamID string, eventType domain.WebhookEventType) ([]*domain.WebhookSubscription, error) {
+	subscriptions := r.filter(func(subscription *domain.WebhookSubscription) bool {
+		return subscription.Matches(teamID, eventType)
+	})
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].ID < subscriptions[j].ID
+	})
+	return subscriptions, nil
+}
+
+func (r *WebhookSubscriptionRepository) filter(match func(subscription *domain.WebhookSubscription) bool) []*domain.WebhookSubscription {
+	subscriptions := make([]*domain.WebhookSubscription, 0)
+	r.tables.read(func(d *data) {
+		for _, stored := range d.subscriptions {
+			if subscription := loadSubscription(stored); match(subscription) {
+				subscriptions = append(subscriptions, subscription)
+			}
+		}
+	})
+	return subscriptions
+}
+
+type WebhookDeliveryRepository struct {
+	tables tables
+}
+
+func NewWebhookDeliveryRepository(store *Store) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{tables: store}
+}
+
+func loadDelivery(delivery domain.WebhookDelivery) *domain.WebhookDelivery {
+	delivery.Payload = slices.Clone(delivery.Payload)
+	delivery.DeliveredAt = copyTime(delivery.DeliveredAt)
+	return &delivery
+}
+
+func (r *WebhookDeliveryRepository) Create(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	if delivery.Status == "" {
+		delivery.Status = domain.WebhookDeliveryPending
+	}
+	return r.tables.write(func(d *data) error {
+		if _, ok := d.subscriptions[delivery.SubscriptionID]; !ok {
+			return notFoundError("webhook %s", delivery.SubscriptionID)
+		}
+		d.lastDeliveryID++
+		delivery.ID = d.lastDeliveryID
+		delivery.CreatedAt = d.now()
+		delivery.NextAttemptAt = delivery.CreatedAt
+		d.deliveries[delivery.ID] = *loadDelivery(*delivery)
+		return nil
+	})
+}
+
+func (r *WebhookDeliveryRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*domain.WebhookDelivery, error) {
+	claimed := make([]*domain.WebhookDelivery, 0)
+	err := r.tables.write(func(d *data) error {
+		for _, delivery := range d.deliveries {
+			if delivery.Status == domain.WebhookDeliveryPending && !delivery.NextAttemptAt.After(now) {
+				claimed = append(claimed, loadDelivery(delivery))
+			}
+		}
+		sort.Slice(claimed, func(i, j int) bool {
+			if c := claimed[i].NextAttemptAt.Compare(claimed[j].NextAttemptAt); c != 0 {
+				return c < 0
+			}
+			return claimed[i].ID < claimed[j].ID
+		})
+		if len(claimed) > limit {
+			claimed = claimed[:max(limit, 0)]
+		}
+
+		for _, delivery := range claimed {
+			delivery.NextAttemptAt = now.Add(lease)
+			d.deliveries[delivery.ID] = *loadDelivery(*delivery)
+		}
+		return nil
+	})
+	return claimed, err
+}
+
+func (r *WebhookDeliveryRepository) SaveAttempt(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	return r.tables.write(func(d *data) error {
+		stored, ok := d.deliveries[delivery.ID]
+		if !ok {
+			return nil
+		}
+		stored.Status = delivery.Status
+		stored.Attempts = delivery.Attempts
+		stored.NextAttemptAt = delivery.NextAttemptAt
+		stored.LastStatusCode = delivery.LastStatusCode
+		stored.LastError = delivery.LastError
+		stored.DeliveredAt = copyTime(delivery.DeliveredAt)
+		d.deliveries[delivery.ID] = stored
+		return nil
+	})
+}
+
+func (r *WebhookDeliveryRepository) ListBySubscription(ctx context.Context, subscriptionID string, status domain.WebhookDeliveryStatus, limit int) ([]*domain.WebhookDelivery, error) {
+	deliveries := make([]*domain.WebhookDelivery, 0)
+	r.tables.read(func(d *data) {
+		for _, delivery := range d.deliveries {
+			if delivery.SubscriptionID == subscriptionID && (status == "" || delivery.Status == status) {
+				deliveries = append(deliveries, loadDelivery(delivery))
+			}
+		}
+	})
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].ID > deliveries[j].ID
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:max(limit, 0)]
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,174 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/danonenka/PR-service/internal/domain"
+	"github.com/danonenka/PR-service/internal/repository/postgres"
+	"github.com/danonenka/PR-service/internal/testdb"
+)
+
+// Бенчмарки гоняются на базе из TEST_DATABASE_URL, схема пересоздаётся
+// из migrations/. Объём истории задают константы ниже.
+const (
+	benchTeams        = 20
+	benchUsersPerTeam = 25
+	benchPRs          = 20000
+)
+
+func openBenchDB(b *testing.B) *sql.DB {
+	b.Helper()
+
+	db := testdb.Open(b)
+	testdb.Migrate(b, db)
+
+	seed(b, db)
+	return db
+}
+
+// seed заполняет базу командами t1..tN с участниками u<team>-<n> и PR,
+// у каждого из которых два ревьюера из команды автора. Каждый третий PR
+// смержен.
+func seed(b *testing.B, db *sql.DB) {
+	b.Helper()
+
+	statements := []string{
+		`INSERT INTO teams (id, name)
+		SELECT 't' || t, 'team-' || t FROM generate_series(1, $1) t`,
+		`INSERT INTO users (id, name, is_active, team_id)
+		SELECT 'u' || t || '-' || n, 'User ' || t || '-' || n, true, 't' || t
+		FROM generate_series(1, $1) t, generate_series(0, $2 - 1) n`,
+		`INSERT INTO pull_requests (id, title, author_id, status, created_at, updated_at)
+		SELECT 'pr-' || i, 'PR ' || i, 'u' || (i % $1 + 1) || '-' || (i % $2),
+			CASE WHEN i % 3 = 0 THEN 'MERGED' ELSE 'OPEN' END,
+			now() - i * interval '1 minute', now() - i * interval '1 minute'
+		FROM generate_series(1, $3) i`,
+		`INSERT INTO reviewer_assignments (pr_id, reviewer_id)
+		SELECT 'pr-' || i, 'u' || (i % $1 + 1) || '-' || ((i + k) % $2)
+		FROM generate_series(1, $3) i, generate_series(1, 2) k`,
+	}
+	args := [][]any{
+		{benchTeams},
+		{benchTeams, benchUsersPerTeam},
+		{benchTeams, benchUsersPerTeam, benchPRs},
+		{benchTeams, benchUsersPerTeam, benchPRs},
+	}
+	for i, statement := range statements {
+		if _, err := db.Exec(statement, args[i]...); err != nil {
+			b.Fatalf("seed: %v", err)
+		}
+	}
+	if _, err := db.Exec(`ANALYZE`); err != nil {
+		b.Fatalf("analyze: %v", err)
+	}
+}
+
+// BenchmarkGetByReviewerIDPerPR воспроизводит прежнюю схему: назначения
+// ревьюера, затем PR и его ревьюеры отдельными запросами. Оставлен для
+// сравнения с BenchmarkGetByReviewerID.
+func BenchmarkGetByReviewerIDPerPR(b *testing.B) {
+	db := openBenchDB(b)
+	prRepo := postgres.NewPullRequestRepository(db)
+	assignmentRepo := postgres.NewReviewerAssignmentRepository(db)
+
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		assignments, err := assignmentRepo.GetByReviewerID(ctx, "u1-1")
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, assignment := range assignments {
+			pr, err := prRepo.GetByID(ctx, assignment.PRID)
+			if err != nil {
+				b.Fatal(err)
+			}
+			reviewers, err := assignmentRepo.GetByPRID(ctx, pr.ID)
+			if err != nil {
+				b.Fatal(err)
+			}
+			pr.ReviewerIDs = make([]string, 0, len(reviewers))
+			for _, reviewer := range reviewers {
+				pr.ReviewerIDs = append(pr.ReviewerIDs, reviewer.ReviewerID)
+			}
+		}
+	}
+}
+
+func BenchmarkGetByReviewerID(b *testing.B) {
+	db := openBenchDB(b)
+	prRepo := postgres.NewPullRequestRepository(db)
+
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := prRepo.GetByReviewerID(ctx, "u1-1"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetOpenByReviewerIDs(b *testing.B) {
+	db := openBenchDB(b)
+	prRepo := postgres.NewPullRequestRepository(db)
+	reviewerIDs := make([]string, 0, benchUsersPerTeam)
+	for n := 0; n < benchUsersPerTeam; n++ {
+		reviewerIDs = append(reviewerIDs, fmt.Sprintf("u1-%d", n))
+	}
+
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := prRepo.GetOpenByReviewerIDs(ctx, reviewerIDs); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReviewerStats(b *testing.B) {
+	db := openBenchDB(b)
+	statsRepo := postgres.NewStatisticsRepository(db)
+
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := statsRepo.ReviewerStats(ctx, domain.StatsFilter{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReviewerStatsByTeam(b *testing.B) {
+	db := openBenchDB(b)
+	statsRepo := postgres.NewStatisticsRepository(db)
+
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := statsRepo.ReviewerStats(ctx, domain.StatsFilter{TeamID: "t1", Status: domain.PRStatusOpen}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPullRequestStats(b *testing.B) {
+	db := openBenchDB(b)
+	statsRepo := postgres.NewStatisticsRepository(db)
+
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := statsRepo.PullRequestStats(ctx, domain.StatsFilter{TeamID: "t1"}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
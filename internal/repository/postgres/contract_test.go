@@ -0,0 +1,29 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/danonenka/PR-service/internal/repository/postgres"
+	"github.com/danonenka/PR-service/internal/repository/repositorytest"
+	"github.com/danonenka/PR-service/internal/testdb"
+)
+
+// TestRepositoryContract гоняет общий контракт на базе из TEST_DATABASE_URL:
+// перед каждым подтестом схема накатывается с нуля, после — откатывается.
+func TestRepositoryContract(t *testing.T) {
+	db := testdb.Open(t)
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Repositories {
+		testdb.Migrate(t, db)
+		return repositorytest.Repositories{
+			Users:                postgres.NewUserRepository(db),
+			Teams:                postgres.NewTeamRepository(db),
+			PullRequests:         postgres.NewPullRequestRepository(db),
+			Assignments:          postgres.NewReviewerAssignmentRepository(db),
+			Events:               postgres.NewPREventRepository(db),
+			WebhookSubscriptions: postgres.NewWebhookSubscriptionRepository(db),
+			WebhookDeliveries:    postgres.NewWebhookDeliveryRepository(db),
+			Identities:           postgres.NewIdentityRepository(db),
+			TxManager:            postgres.NewTxManager(db),
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/danonenka/PR-service/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+// Коды ошибок Postgres, которые переводятся в доменные ошибки.
+const (
+	codeUniqueViolation      = "23505"
+	codeForeignKeyViolation  = "23503"
+	codeCheckViolation       = "23514"
+	codeStringTooLong        = "22001"
+	codeSerializationFailure = "40001"
+	codeDeadlockDetected     = "40P01"
+)
+
+// translateError переводит ошибки драйвера в ошибки domain, чтобы слои
+// выше не разбирали тексты сообщений Postgres. Исходная ошибка остаётся
+// в цепочке. Уже переведённые и прочие ошибки возвращаются как есть.
+func translateError(err error) error {
+	if err == nil || isTranslated(err) {
+		return err
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %w", domain.ErrNotFound, err)
+	}
+
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+	switch pqErr.Code {
+	case codeUniqueViolation:
+		return fmt.Errorf("%w: %w", domain.ErrConflict, err)
+	case codeForeignKeyViolation:
+		// Ссылка на несуществующую строку: команду, пользователя или PR
+		return fmt.Errorf("%w: %w", domain.ErrNotFound, err)
+	case codeCheckViolation, codeStringTooLong:
+		return fmt.Errorf("%w: %w", domain.ErrInvalidArgument, err)
+	case codeSerializationFailure, codeDeadlockDetected:
+		return fmt.Errorf("%w: %w", domain.ErrConcurrentModification, err)
+	}
+	return err
+}
+
+// isTranslated сообщает, что ошибка уже переведена: так бывает, когда
+// ошибку репозитория повторно переводит WithinTx.
+func isTranslated(err error) bool {
+	for _, target := range []error{domain.ErrNotFound, domain.ErrConflict, domain.ErrInvalidArgument, domain.ErrConcurrentModification} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/danonenka/PR-service/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+func TestTranslateError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		target error
+	}{
+		{name: "no rows", err: sql.ErrNoRows, target: domain.ErrNotFound},
+		{name: "unique", err: &pq.Error{Code: codeUniqueViolation}, target: domain.ErrConflict},
+		{name: "foreign key", err: &pq.Error{Code: codeForeignKeyViolation}, target: domain.ErrNotFound},
+		{name: "check", err: &pq.Error{Code: codeCheckViolation}, target: domain.ErrInvalidArgument},
+		{name: "serialization", err: &pq.Error{Code: codeSerializationFailure}, target: domain.ErrConcurrentModification},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			translated := translateError(tt.err)
+			if !errors.Is(translated, tt.target) || !errors.Is(translated, tt.err) {
+				t.Fatalf("expected %v wrapping %v, got %v", tt.target, tt.err, translated)
+			}
+			// WithinTx переводит ошибки репозиториев повторно
+			if again := translateError(translated); again != translated {
+				t.Errorf("translated twice: %v", again)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/danonenka/PR-service/internal/domain"
+)
+
+type IdentityRepository struct {
+	db querier
+}
+
+func NewIdentityRepository(db *sql.DB) *IdentityRepository {
+	return &IdentityRepository{db: logQueries(db)}
+}
+
+func (r *IdentityRepository) Upsert(ctx context.Context, mapping *domain.IdentityMapping) error {
+	mapping.Login = strings.ToLower(mapping.Login)
+	query := `
+		INSERT INTO identity_mappings (provider, external_login, user_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (provider, external_login) DO UPDATE SET user_id = EXCLUDED.user_id
+		RETURNING created_at
+	`
+	return translateError(r.db.QueryRowContext(ctx, query, mapping.Provider, mapping.Login, mapping.UserID).Scan(&mapping.CreatedAt))
+}
+
+func (r *IdentityRepository) GetUserID(ctx context.Context, provider, login string) (string, error) {
+	var userID string
+	query := `SELECT user_id FROM identity_mappings WHERE provider = $1 AND external_login = $2`
+	err := r.db.QueryRowContext(ctx, query, provider, strings.ToLower(login)).Scan(&userID)
+	return userID, translateError(err)
+}
+
+func (r *IdentityRepository) GetByProvider(ctx context.Context, provider string) ([]*domain.IdentityMapping, error) {
+	query := `
+		SELECT provider, external_login, user_id, created_at
+		FROM identity_mappings
+		WHERE provider = $1
+		ORDER BY external_login
+	`
+	rows, err := r.db.QueryContext(ctx, query, provider)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	mappings := make([]*domain.IdentityMapping, 0)
+	for rows.Next() {
+		mapping := &domain.IdentityMapping{}
+		if err := rows.Scan(&mapping.Provider, &mapping.Login, &mapping.UserID, &mapping.CreatedAt); err != nil {
+			return nil, translateError(err)
+		}
+		mappings = append(mappings, mapping)
+	}
+	return mappings, rows.Err()
+}
+
+type InboundDeliveryRepository struct {
+	db querier
+}
+
+func NewInboundDeliveryRepository(db *sql.DB) *InboundDeliveryRepository {
+	return &InboundDeliveryRepository{db: logQueries(db)}
+}
+
+func (r *InboundDeliveryRepository) Claim(ctx context.Context, provider, deliveryID, event string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO inbound_deliveries (provider, delivery_id, event)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (provider, delivery_id) DO NOTHING
+	`, provider, deliveryID, event)
+	if err != nil {
+		return false, translateError(err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, translateError(err)
+	}
+	return affected == 1, nil
+}
+
+func (r *InboundDeliveryRepository) Release(ctx context.Context, provider, deliveryID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM inbound_deliveries WHERE provider = $1 AND delivery_id = $2`, provider, deliveryID)
+	return translateError(err)
+}
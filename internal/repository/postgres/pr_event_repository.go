@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/danonenka/PR-service/internal/domain"
+)
+
+type PREventRepository struct {
+	db querier
+}
+
+func NewPREventRepository(db *sql.DB) *PREventRepository {
+	return &PREventRepository{db: logQueries(db)}
+}
+
+func (r *PREventRepository) Create(ctx context.Context, event *domain.PREvent) error {
+	query := `
+		INSERT INTO pr_events (pr_id, event_type, actor_id, reviewer_id, reason, from_status, to_status, review_state)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''))
+		RETURNING id, created_at
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		event.PRID, event.Type, event.ActorID, event.ReviewerID, event.Reason, event.FromStatus, event.ToStatus, event.ReviewState,
+	).Scan(&event.ID, &event.CreatedAt)
+	return translateError(err)
+}
+
+func (r *PREventRepository) GetByPRID(ctx context.Context, prID string) ([]*domain.PREvent, error) {
+	query := `
+		SELECT id, pr_id, event_type,
+			COALESCE(actor_id, ''), COALESCE(reviewer_id, ''), COALESCE(reason, ''),
+			COALESCE(from_status, ''), COALESCE(to_status, ''), COALESCE(review_state, ''), created_at
+		FROM pr_events
+		WHERE pr_id = $1
+		ORDER BY id
+	`
+	rows, err := r.db.QueryContext(ctx, query, prID)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	events := make([]*domain.PREvent, 0)
+	for rows.Next() {
+		event := &domain.PREvent{}
+		if err := rows.Scan(&event.ID, &event.PRID, &event.Type, &event.ActorID, &event.ReviewerID,
+			&event.Reason, &event.FromStatus, &event.ToStatus, &event.ReviewState, &event.CreatedAt); err != nil {
+			return nil, translateError(err)
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
@@ -1,99 +1,224 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"strings"
+
 	"github.com/danonenka/PR-service/internal/domain"
+
+	"github.com/lib/pq"
 )
 
+const prColumns = `id, title, author_id, status, created_at, merged_at, closed_at, updated_at, version`
+
 type PullRequestRepository struct {
-	db *sql.DB
+	db querier
 }
 
 func NewPullRequestRepository(db *sql.DB) *PullRequestRepository {
-	return &PullRequestRepository{db: db}
+	return &PullRequestRepository{db: logQueries(db)}
 }
 
-func (r *PullRequestRepository) Create(pr *domain.PullRequest) error {
-	query := `INSERT INTO pull_requests (id, title, author_id, status) VALUES ($1, $2, $3, $4)`
-	_, err := r.db.Exec(query, pr.ID, pr.Title, pr.AuthorID, pr.Status)
-	return err
+type rowScanner interface {
+	Scan(dest ...any) error
 }
 
-func (r *PullRequestRepository) GetByID(id string) (*domain.PullRequest, error) {
-	query := `SELECT id, title, author_id, status FROM pull_requests WHERE id = $1`
+func scanPullRequest(row rowScanner) (*domain.PullRequest, error) {
 	pr := &domain.PullRequest{}
-	err := r.db.QueryRow(query, id).Scan(&pr.ID, &pr.Title, &pr.AuthorID, &pr.Status)
-	if err == sql.ErrNoRows {
-		return nil, err
+	var mergedAt, closedAt sql.NullTime
+	if err := row.Scan(&pr.ID, &pr.Title, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &mergedAt, &closedAt, &pr.UpdatedAt, &pr.Version); err != nil {
+		return nil, translateError(err)
 	}
-	return pr, err
+	setNullableTimes(pr, mergedAt, closedAt)
+	return pr, nil
 }
 
-func (r *PullRequestRepository) GetByAuthorID(authorID string) ([]*domain.PullRequest, error) {
-	query := `SELECT id, title, author_id, status FROM pull_requests WHERE author_id = $1`
-	rows, err := r.db.Query(query, authorID)
-	if err != nil {
-		return nil, err
+func setNullableTimes(pr *domain.PullRequest, mergedAt, closedAt sql.NullTime) {
+	if mergedAt.Valid {
+		pr.MergedAt = &mergedAt.Time
 	}
+	if closedAt.Valid {
+		pr.ClosedAt = &closedAt.Time
+	}
+}
+
+func scanPullRequests(rows *sql.Rows) ([]*domain.PullRequest, error) {
 	defer rows.Close()
 
 	prs := make([]*domain.PullRequest, 0)
 	for rows.Next() {
-		pr := &domain.PullRequest{}
-		if err := rows.Scan(&pr.ID, &pr.Title, &pr.AuthorID, &pr.Status); err != nil {
-			return nil, err
+		pr, err := scanPullRequest(rows)
+		if err != nil {
+			return nil, translateError(err)
 		}
 		prs = append(prs, pr)
 	}
 	return prs, rows.Err()
 }
 
-func (r *PullRequestRepository) GetByReviewerID(reviewerID string) ([]*domain.PullRequest, error) {
-	query := `
-		SELECT pr.id, pr.title, pr.author_id, pr.status 
+// selectPRsWithReviewers собирает PR вместе с ревьюерами одним запросом:
+// назначения агрегируются в массив, а не читаются отдельно на каждый PR.
+// where и orderBy могут ссылаться на pull_requests через алиас pr.
+func selectPRsWithReviewers(where string, orderBy string) string {
+	return `
+		SELECT pr.id, pr.title, pr.author_id, pr.status, pr.created_at, pr.merged_at, pr.closed_at, pr.updated_at, pr.version,
+			COALESCE(array_agg(ra.reviewer_id ORDER BY ra.assigned_at, ra.reviewer_id) FILTER (WHERE ra.reviewer_id IS NOT NULL), '{}')
 		FROM pull_requests pr
-		INNER JOIN reviewer_assignments ra ON pr.id = ra.pr_id
-		WHERE ra.reviewer_id = $1
+		LEFT JOIN reviewer_assignments ra ON ra.pr_id = pr.id
+		WHERE ` + where + `
+		GROUP BY pr.id
+		ORDER BY ` + orderBy + `
 	`
-	rows, err := r.db.Query(query, reviewerID)
-	if err != nil {
-		return nil, err
-	}
+}
+
+const orderByCreation = `pr.created_at, pr.id`
+
+func scanPullRequestsWithReviewers(rows *sql.Rows) ([]*domain.PullRequest, error) {
 	defer rows.Close()
 
 	prs := make([]*domain.PullRequest, 0)
 	for rows.Next() {
 		pr := &domain.PullRequest{}
-		if err := rows.Scan(&pr.ID, &pr.Title, &pr.AuthorID, &pr.Status); err != nil {
-			return nil, err
+		var mergedAt, closedAt sql.NullTime
+		var reviewerIDs pq.StringArray
+		if err := rows.Scan(&pr.ID, &pr.Title, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &mergedAt, &closedAt, &pr.UpdatedAt, &pr.Version, &reviewerIDs); err != nil {
+			return nil, translateError(err)
 		}
+		setNullableTimes(pr, mergedAt, closedAt)
+		pr.ReviewerIDs = []string(reviewerIDs)
 		prs = append(prs, pr)
 	}
 	return prs, rows.Err()
 }
 
-func (r *PullRequestRepository) Update(pr *domain.PullRequest) error {
-	query := `UPDATE pull_requests SET title = $2, author_id = $3, status = $4 WHERE id = $1`
-	_, err := r.db.Exec(query, pr.ID, pr.Title, pr.AuthorID, pr.Status)
-	return err
+func (r *PullRequestRepository) Create(ctx context.Context, pr *domain.PullRequest) error {
+	query := `
+		INSERT INTO pull_requests (id, title, author_id, status, created_at, merged_at, closed_at, updated_at, version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 1)
+		RETURNING version
+	`
+	err := r.db.QueryRowContext(ctx, query, pr.ID, pr.Title, pr.AuthorID, pr.Status, pr.CreatedAt, pr.MergedAt, pr.ClosedAt, pr.UpdatedAt).Scan(&pr.Version)
+	return translateError(err)
 }
 
-func (r *PullRequestRepository) GetAll() ([]*domain.PullRequest, error) {
-	query := `SELECT id, title, author_id, status FROM pull_requests`
-	rows, err := r.db.Query(query)
+func (r *PullRequestRepository) GetByID(ctx context.Context, id string) (*domain.PullRequest, error) {
+	query := `SELECT ` + prColumns + ` FROM pull_requests WHERE id = $1`
+	return scanPullRequest(r.db.QueryRowContext(ctx, query, id))
+}
+
+func (r *PullRequestRepository) GetByIDForUpdate(ctx context.Context, id string) (*domain.PullRequest, error) {
+	query := `SELECT ` + prColumns + ` FROM pull_requests WHERE id = $1 FOR UPDATE`
+	return scanPullRequest(r.db.QueryRowContext(ctx, query, id))
+}
+
+func (r *PullRequestRepository) GetByAuthorID(ctx context.Context, authorID string) ([]*domain.PullRequest, error) {
+	rows, err := r.db.QueryContext(ctx, selectPRsWithReviewers(`pr.author_id = $1`, orderByCreation), authorID)
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
-	defer rows.Close()
+	return scanPullRequestsWithReviewers(rows)
+}
 
-	prs := make([]*domain.PullRequest, 0)
-	for rows.Next() {
-		pr := &domain.PullRequest{}
-		if err := rows.Scan(&pr.ID, &pr.Title, &pr.AuthorID, &pr.Status); err != nil {
-			return nil, err
-		}
-		prs = append(prs, pr)
+func (r *PullRequestRepository) GetByReviewerID(ctx context.Context, reviewerID string) ([]*domain.PullRequest, error) {
+	where := `pr.id IN (SELECT pr_id FROM reviewer_assignments WHERE reviewer_id = $1)`
+	rows, err := r.db.QueryContext(ctx, selectPRsWithReviewers(where, orderByCreation), reviewerID)
+	if err != nil {
+		return nil, translateError(err)
 	}
-	return prs, rows.Err()
+	return scanPullRequestsWithReviewers(rows)
+}
+
+func (r *PullRequestRepository) GetOpenByReviewerIDs(ctx context.Context, reviewerIDs []string) ([]*domain.PullRequest, error) {
+	if len(reviewerIDs) == 0 {
+		return []*domain.PullRequest{}, nil
+	}
+
+	where := `pr.status = 'OPEN' AND pr.id IN (SELECT pr_id FROM reviewer_assignments WHERE reviewer_id = ANY($1))`
+	rows, err := r.db.QueryContext(ctx, selectPRsWithReviewers(where, orderByCreation), pq.Array(reviewerIDs))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return scanPullRequestsWithReviewers(rows)
+}
+
+// List использует keyset-пагинацию: страница начинается сразу после курсора
+// по (поле сортировки, id), поэтому глубокие страницы не дороже первой.
+func (r *PullRequestRepository) List(ctx context.Context, filter domain.PRListFilter) ([]*domain.PullRequest, error) {
+	conditions := []string{"TRUE"}
+	args := make([]any, 0, 8)
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.AuthorID != "" {
+		conditions = append(conditions, `pr.author_id = `+arg(filter.AuthorID))
+	}
+	if filter.TeamID != "" {
+		conditions = append(conditions, `pr.author_id IN (SELECT id FROM users WHERE team_id = `+arg(filter.TeamID)+`)`)
+	}
+	if filter.ReviewerID != "" {
+		conditions = append(conditions, `pr.id IN (SELECT pr_id FROM reviewer_assignments WHERE reviewer_id = `+arg(filter.ReviewerID)+`)`)
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, `pr.status = `+arg(filter.Status))
+	}
+	if filter.CreatedFrom != nil {
+		conditions = append(conditions, `pr.created_at >= `+arg(*filter.CreatedFrom))
+	}
+	if filter.CreatedTo != nil {
+		conditions = append(conditions, `pr.created_at < `+arg(*filter.CreatedTo))
+	}
+
+	// Колонка подставляется в запрос только из белого списка
+	column := "pr.created_at"
+	if filter.SortBy == domain.PRSortUpdatedAt {
+		column = "pr.updated_at"
+	}
+	direction, comparison := "ASC", ">"
+	if filter.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	if filter.After != nil {
+		conditions = append(conditions, `(`+column+`, pr.id) `+comparison+` (`+arg(filter.After.Value)+`, `+arg(filter.After.ID)+`)`)
+	}
+
+	orderBy := column + ` ` + direction + `, pr.id ` + direction
+	query := selectPRsWithReviewers(strings.Join(conditions, " AND "), orderBy)
+	if filter.Limit > 0 {
+		query += ` LIMIT ` + arg(filter.Limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return scanPullRequestsWithReviewers(rows)
+}
+
+func (r *PullRequestRepository) Update(ctx context.Context, pr *domain.PullRequest) error {
+	query := `
+		UPDATE pull_requests
+		SET title = $2, author_id = $3, status = $4, merged_at = $5, closed_at = $6, updated_at = $7, version = version + 1
+		WHERE id = $1 AND version = $8
+		RETURNING version
+	`
+	err := r.db.QueryRowContext(ctx, query, pr.ID, pr.Title, pr.AuthorID, pr.Status, pr.MergedAt, pr.ClosedAt, pr.UpdatedAt, pr.Version).Scan(&pr.Version)
+	if err == sql.ErrNoRows {
+		return domain.ErrConcurrentModification
+	}
+	return translateError(err)
+}
+
+func (r *PullRequestRepository) GetAll(ctx context.Context) ([]*domain.PullRequest, error) {
+	query := `SELECT ` + prColumns + ` FROM pull_requests`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return scanPullRequests(rows)
 }
 
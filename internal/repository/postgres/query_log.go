@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// loggedQuerier пишет каждый запрос в debug-лог вместе с длительностью и
+// ошибкой. Логгер берёт request_id из ctx, поэтому запросы видны рядом с
+// HTTP-запросом, который их вызвал.
+type loggedQuerier struct {
+	querier
+}
+
+func logQueries(db querier) querier {
+	return loggedQuerier{querier: db}
+}
+
+func (q loggedQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	start := time.Now()
+	result, err := q.querier.ExecContext(ctx, query, args...)
+	logQuery(ctx, query, start, err)
+	return result, err
+}
+
+func (q loggedQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := q.querier.QueryContext(ctx, query, args...)
+	logQuery(ctx, query, start, err)
+	return rows, err
+}
+
+func (q loggedQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	start := time.Now()
+	row := q.querier.QueryRowContext(ctx, query, args...)
+	logQuery(ctx, query, start, row.Err())
+	return row
+}
+
+func logQuery(ctx context.Context, query string, start time.Time, err error) {
+	if !slog.Default().Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	attrs := []any{
+		slog.String("query", strings.Join(strings.Fields(query), " ")),
+		slog.Duration("duration", time.Since(start)),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	slog.DebugContext(ctx, "sql query", attrs...)
+}
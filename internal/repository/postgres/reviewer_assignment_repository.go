@@ -1,71 +1,127 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"github.com/danonenka/PR-service/internal/domain"
+
+	"github.com/lib/pq"
 )
 
+const assignmentColumns = `pr_id, reviewer_id, state, assigned_at, reviewed_at`
+
+func scanAssignments(rows *sql.Rows) ([]*domain.ReviewerAssignment, error) {
+	defer rows.Close()
+
+	assignments := make([]*domain.ReviewerAssignment, 0)
+	for rows.Next() {
+		assignment := &domain.ReviewerAssignment{}
+		var reviewedAt sql.NullTime
+		if err := rows.Scan(&assignment.PRID, &assignment.ReviewerID, &assignment.State, &assignment.AssignedAt, &reviewedAt); err != nil {
+			return nil, translateError(err)
+		}
+		if reviewedAt.Valid {
+			assignment.ReviewedAt = &reviewedAt.Time
+		}
+		assignments = append(assignments, assignment)
+	}
+	return assignments, rows.Err()
+}
+
 type ReviewerAssignmentRepository struct {
-	db *sql.DB
+	db querier
 }
 
 func NewReviewerAssignmentRepository(db *sql.DB) *ReviewerAssignmentRepository {
-	return &ReviewerAssignmentRepository{db: db}
+	return &ReviewerAssignmentRepository{db: logQueries(db)}
 }
 
-func (r *ReviewerAssignmentRepository) Create(assignment *domain.ReviewerAssignment) error {
-	query := `INSERT INTO reviewer_assignments (pr_id, reviewer_id) VALUES ($1, $2)`
-	_, err := r.db.Exec(query, assignment.PRID, assignment.ReviewerID)
-	return err
+// Create сохраняет назначение в состоянии PENDING, если State не задан.
+func (r *ReviewerAssignmentRepository) Create(ctx context.Context, assignment *domain.ReviewerAssignment) error {
+	if assignment.State == "" {
+		assignment.State = domain.ReviewStatePending
+	}
+	query := `
+		INSERT INTO reviewer_assignments (pr_id, reviewer_id, state, reviewed_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING assigned_at
+	`
+	err := r.db.QueryRowContext(ctx, query, assignment.PRID, assignment.ReviewerID, assignment.State, assignment.ReviewedAt).Scan(&assignment.AssignedAt)
+	return translateError(err)
 }
 
-func (r *ReviewerAssignmentRepository) Delete(prID string, reviewerID string) error {
+func (r *ReviewerAssignmentRepository) Delete(ctx context.Context, prID string, reviewerID string) error {
 	query := `DELETE FROM reviewer_assignments WHERE pr_id = $1 AND reviewer_id = $2`
-	_, err := r.db.Exec(query, prID, reviewerID)
-	return err
+	_, err := r.db.ExecContext(ctx, query, prID, reviewerID)
+	return translateError(err)
 }
 
-func (r *ReviewerAssignmentRepository) GetByPRID(prID string) ([]*domain.ReviewerAssignment, error) {
-	query := `SELECT pr_id, reviewer_id FROM reviewer_assignments WHERE pr_id = $1`
-	rows, err := r.db.Query(query, prID)
+func (r *ReviewerAssignmentRepository) GetByPRID(ctx context.Context, prID string) ([]*domain.ReviewerAssignment, error) {
+	query := `SELECT ` + assignmentColumns + ` FROM reviewer_assignments WHERE pr_id = $1 ORDER BY assigned_at, reviewer_id`
+	rows, err := r.db.QueryContext(ctx, query, prID)
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
-	defer rows.Close()
+	return scanAssignments(rows)
+}
 
-	assignments := make([]*domain.ReviewerAssignment, 0)
-	for rows.Next() {
-		assignment := &domain.ReviewerAssignment{}
-		if err := rows.Scan(&assignment.PRID, &assignment.ReviewerID); err != nil {
-			return nil, err
-		}
-		assignments = append(assignments, assignment)
+func (r *ReviewerAssignmentRepository) GetByReviewerID(ctx context.Context, reviewerID string) ([]*domain.ReviewerAssignment, error) {
+	query := `SELECT ` + assignmentColumns + ` FROM reviewer_assignments WHERE reviewer_id = $1`
+	rows, err := r.db.QueryContext(ctx, query, reviewerID)
+	if err != nil {
+		return nil, translateError(err)
 	}
-	return assignments, rows.Err()
+	return scanAssignments(rows)
+}
+
+func (r *ReviewerAssignmentRepository) DeleteByPRID(ctx context.Context, prID string) error {
+	query := `DELETE FROM reviewer_assignments WHERE pr_id = $1`
+	_, err := r.db.ExecContext(ctx, query, prID)
+	return translateError(err)
+}
+
+func (r *ReviewerAssignmentRepository) UpdateState(ctx context.Context, assignment *domain.ReviewerAssignment) error {
+	query := `UPDATE reviewer_assignments SET state = $3, reviewed_at = $4 WHERE pr_id = $1 AND reviewer_id = $2`
+	result, err := r.db.ExecContext(ctx, query, assignment.PRID, assignment.ReviewerID, assignment.State, assignment.ReviewedAt)
+	if err != nil {
+		return translateError(err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return translateError(err)
+	}
+	if affected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
 }
 
-func (r *ReviewerAssignmentRepository) GetByReviewerID(reviewerID string) ([]*domain.ReviewerAssignment, error) {
-	query := `SELECT pr_id, reviewer_id FROM reviewer_assignments WHERE reviewer_id = $1`
-	rows, err := r.db.Query(query, reviewerID)
+func (r *ReviewerAssignmentRepository) CountOpenByReviewerIDs(ctx context.Context, reviewerIDs []string) (map[string]int, error) {
+	counts := make(map[string]int)
+	if len(reviewerIDs) == 0 {
+		return counts, nil
+	}
+
+	query := `
+		SELECT ra.reviewer_id, COUNT(*)
+		FROM reviewer_assignments ra
+		INNER JOIN pull_requests pr ON pr.id = ra.pr_id
+		WHERE pr.status = 'OPEN' AND ra.reviewer_id = ANY($1)
+		GROUP BY ra.reviewer_id
+	`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(reviewerIDs))
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 	defer rows.Close()
 
-	assignments := make([]*domain.ReviewerAssignment, 0)
 	for rows.Next() {
-		assignment := &domain.ReviewerAssignment{}
-		if err := rows.Scan(&assignment.PRID, &assignment.ReviewerID); err != nil {
-			return nil, err
+		var reviewerID string
+		var count int
+		if err := rows.Scan(&reviewerID, &count); err != nil {
+			return nil, translateError(err)
 		}
-		assignments = append(assignments, assignment)
+		counts[reviewerID] = count
 	}
-	return assignments, rows.Err()
+	return counts, rows.Err()
 }
-
-func (r *ReviewerAssignmentRepository) DeleteByPRID(prID string) error {
-	query := `DELETE FROM reviewer_assignments WHERE pr_id = $1`
-	_, err := r.db.Exec(query, prID)
-	return err
-}
-
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/danonenka/PR-service/internal/domain"
+)
+
+type StatisticsRepository struct {
+	db querier
+}
+
+func NewStatisticsRepository(db *sql.DB) *StatisticsRepository {
+	return &StatisticsRepository{db: logQueries(db)}
+}
+
+// statsConditions строит WHERE по фильтру. teamColumn — колонка team_id,
+// по которой фильтруется команда (ревьюера или автора).
+func statsConditions(filter domain.StatsFilter, teamColumn string) (string, []any) {
+	conditions := make([]string, 0, 4)
+	args := make([]any, 0, 4)
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.TeamID != "" {
+		add(teamColumn+` = $%d`, filter.TeamID)
+	}
+	if filter.Status != "" {
+		add(`pr.status = $%d`, filter.Status)
+	}
+	if filter.From != nil {
+		add(`pr.created_at >= $%d`, *filter.From)
+	}
+	if filter.To != nil {
+		add(`pr.created_at < $%d`, *filter.To)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+func (r *StatisticsRepository) ReviewerStats(ctx context.Context, filter domain.StatsFilter) ([]*domain.ReviewerStats, error) {
+	where, args := statsConditions(filter, "u.team_id")
+	query := `
+		SELECT u.id, u.name,
+			COUNT(*) AS assignments,
+			COUNT(*) FILTER (WHERE pr.status = 'OPEN') AS open_assignments,
+			COUNT(*) FILTER (WHERE pr.status = 'MERGED') AS merged_assignments
+		FROM reviewer_assignments ra
+		INNER JOIN pull_requests pr ON pr.id = ra.pr_id
+		INNER JOIN users u ON u.id = ra.reviewer_id
+		` + where + `
+		GROUP BY u.id, u.name
+		ORDER BY open_assignments DESC, assignments DESC, u.id
+	`
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	stats := make([]*domain.ReviewerStats, 0)
+	for rows.Next() {
+		stat := &domain.ReviewerStats{}
+		if err := rows.Scan(&stat.UserID, &stat.UserName, &stat.Assignments, &stat.OpenAssignments, &stat.MergedAssignments); err != nil {
+			return nil, translateError(err)
+		}
+		stats = append(stats, stat)
+	}
+	return stats, rows.Err()
+}
+
+func (r *StatisticsRepository) PullRequestStats(ctx context.Context, filter domain.StatsFilter) ([]*domain.PullRequestStats, error) {
+	where, args := statsConditions(filter, "author.team_id")
+	query := `
+		SELECT pr.id, pr.title, pr.author_id, pr.status, COUNT(ra.reviewer_id)
+		FROM pull_requests pr
+		INNER JOIN users author ON author.id = pr.author_id
+		LEFT JOIN reviewer_assignments ra ON ra.pr_id = pr.id
+		` + where + `
+		GROUP BY pr.id
+		ORDER BY pr.created_at, pr.id
+	`
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	stats := make([]*domain.PullRequestStats, 0)
+	for rows.Next() {
+		stat := &domain.PullRequestStats{}
+		if err := rows.Scan(&stat.PRID, &stat.PRTitle, &stat.AuthorID, &stat.Status, &stat.Assignments); err != nil {
+			return nil, translateError(err)
+		}
+		stats = append(stats, stat)
+	}
+	return stats, rows.Err()
+}
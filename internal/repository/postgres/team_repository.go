@@ -1,72 +1,73 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"github.com/danonenka/PR-service/internal/domain"
 )
 
+const teamColumns = `id, name, reviewer_strategy, min_reviewers, max_reviewers, required_approvals`
+
+func scanTeam(row rowScanner) (*domain.Team, error) {
+	team := &domain.Team{}
+	if err := row.Scan(&team.ID, &team.Name, &team.ReviewerStrategy, &team.MinReviewers, &team.MaxReviewers, &team.RequiredApprovals); err != nil {
+		return nil, translateError(err)
+	}
+	return team, nil
+}
+
 type TeamRepository struct {
-	db *sql.DB
+	db querier
 }
 
 func NewTeamRepository(db *sql.DB) *TeamRepository {
-	return &TeamRepository{db: db}
+	return &TeamRepository{db: logQueries(db)}
 }
 
-func (r *TeamRepository) Create(team *domain.Team) error {
-	query := `INSERT INTO teams (id, name) VALUES ($1, $2)`
-	_, err := r.db.Exec(query, team.ID, team.Name)
-	return err
+func (r *TeamRepository) Create(ctx context.Context, team *domain.Team) error {
+	query := `INSERT INTO teams (` + teamColumns + `) VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := r.db.ExecContext(ctx, query, team.ID, team.Name, team.ReviewerStrategy, team.MinReviewers, team.MaxReviewers, team.RequiredApprovals)
+	return translateError(err)
 }
 
-func (r *TeamRepository) GetByID(id string) (*domain.Team, error) {
-	query := `SELECT id, name FROM teams WHERE id = $1`
-	team := &domain.Team{}
-	err := r.db.QueryRow(query, id).Scan(&team.ID, &team.Name)
-	if err == sql.ErrNoRows {
-		return nil, err
-	}
-	return team, err
+func (r *TeamRepository) GetByID(ctx context.Context, id string) (*domain.Team, error) {
+	query := `SELECT ` + teamColumns + ` FROM teams WHERE id = $1`
+	return scanTeam(r.db.QueryRowContext(ctx, query, id))
 }
 
-func (r *TeamRepository) GetByName(name string) (*domain.Team, error) {
-	query := `SELECT id, name FROM teams WHERE name = $1`
-	team := &domain.Team{}
-	err := r.db.QueryRow(query, name).Scan(&team.ID, &team.Name)
-	if err == sql.ErrNoRows {
-		return nil, err
-	}
-	return team, err
+func (r *TeamRepository) GetByName(ctx context.Context, name string) (*domain.Team, error) {
+	query := `SELECT ` + teamColumns + ` FROM teams WHERE name = $1`
+	return scanTeam(r.db.QueryRowContext(ctx, query, name))
 }
 
-func (r *TeamRepository) GetAll() ([]*domain.Team, error) {
-	query := `SELECT id, name FROM teams`
-	rows, err := r.db.Query(query)
+func (r *TeamRepository) GetAll(ctx context.Context) ([]*domain.Team, error) {
+	query := `SELECT ` + teamColumns + ` FROM teams`
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 	defer rows.Close()
 
 	teams := make([]*domain.Team, 0)
 	for rows.Next() {
-		team := &domain.Team{}
-		if err := rows.Scan(&team.ID, &team.Name); err != nil {
-			return nil, err
+		team, err := scanTeam(rows)
+		if err != nil {
+			return nil, translateError(err)
 		}
 		teams = append(teams, team)
 	}
 	return teams, rows.Err()
 }
 
-func (r *TeamRepository) Update(team *domain.Team) error {
-	query := `UPDATE teams SET name = $2 WHERE id = $1`
-	_, err := r.db.Exec(query, team.ID, team.Name)
-	return err
+func (r *TeamRepository) Update(ctx context.Context, team *domain.Team) error {
+	query := `UPDATE teams SET name = $2, reviewer_strategy = $3, min_reviewers = $4, max_reviewers = $5, required_approvals = $6 WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, team.ID, team.Name, team.ReviewerStrategy, team.MinReviewers, team.MaxReviewers, team.RequiredApprovals)
+	return translateError(err)
 }
 
-func (r *TeamRepository) Delete(id string) error {
+func (r *TeamRepository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM teams WHERE id = $1`
-	_, err := r.db.Exec(query, id)
-	return err
+	_, err := r.db.ExecContext(ctx, query, id)
+	return translateError(err)
 }
 
@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/danonenka/PR-service/internal/domain"
+)
+
+// querier — общее подмножество *sql.DB и *sql.Tx, через которое работают
+// репозитории.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type TxManager struct {
+	db *sql.DB
+}
+
+func NewTxManager(db *sql.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+func (m *TxManager) WithinTx(ctx context.Context, fn func(uow domain.UnitOfWork) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return translateError(err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(newUnitOfWork(tx)); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			slog.ErrorContext(ctx, "transaction rollback failed", "error", rbErr)
+			return errors.Join(err, rbErr)
+		}
+		return translateError(err)
+	}
+
+	return translateError(tx.Commit())
+}
+
+type unitOfWork struct {
+	users        *UserRepository
+	teams        *TeamRepository
+	pullRequests *PullRequestRepository
+	assignments  *ReviewerAssignmentRepository
+	events       *PREventRepository
+	webhooks     *WebhookSubscriptionRepository
+	deliveries   *WebhookDeliveryRepository
+}
+
+func newUnitOfWork(tx *sql.Tx) *unitOfWork {
+	db := logQueries(tx)
+	return &unitOfWork{
+		users:        &UserRepository{db: db},
+		teams:        &TeamRepository{db: db},
+		pullRequests: &PullRequestRepository{db: db},
+		assignments:  &ReviewerAssignmentRepository{db: db},
+		events:       &PREventRepository{db: db},
+		webhooks:     &WebhookSubscriptionRepository{db: db},
+		deliveries:   &WebhookDeliveryRepository{db: db},
+	}
+}
+
+func (u *unitOfWork) Users() domain.UserRepository {
+	return u.users
+}
+
+func (u *unitOfWork) Teams() domain.TeamRepository {
+	return u.teams
+}
+
+func (u *unitOfWork) PullRequests() domain.PullRequestRepository {
+	return u.pullRequests
+}
+
+func (u *unitOfWork) Assignments() domain.ReviewerAssignmentRepository {
+	return u.assignments
+}
+
+func (u *unitOfWork) Events() domain.PREventRepository {
+	return u.events
+}
+
+func (u *unitOfWork) WebhookSubscriptions() domain.WebhookSubscriptionRepository {
+	return u.webhooks
+}
+
+func (u *unitOfWork) WebhookDeliveries() domain.WebhookDeliveryRepository {
+	return u.deliveries
+}
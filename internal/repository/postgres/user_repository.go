@@ -1,39 +1,42 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"github.com/danonenka/PR-service/internal/domain"
+
+	"github.com/lib/pq"
 )
 
 type UserRepository struct {
-	db *sql.DB
+	db querier
 }
 
 func NewUserRepository(db *sql.DB) *UserRepository {
-	return &UserRepository{db: db}
+	return &UserRepository{db: logQueries(db)}
 }
 
-func (r *UserRepository) Create(user *domain.User) error {
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
 	query := `INSERT INTO users (id, name, is_active, team_id) VALUES ($1, $2, $3, $4)`
-	_, err := r.db.Exec(query, user.ID, user.Name, user.IsActive, user.TeamID)
-	return err
+	_, err := r.db.ExecContext(ctx, query, user.ID, user.Name, user.IsActive, user.TeamID)
+	return translateError(err)
 }
 
-func (r *UserRepository) GetByID(id string) (*domain.User, error) {
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
 	query := `SELECT id, name, is_active, team_id FROM users WHERE id = $1`
 	user := &domain.User{}
-	err := r.db.QueryRow(query, id).Scan(&user.ID, &user.Name, &user.IsActive, &user.TeamID)
-	if err == sql.ErrNoRows {
-		return nil, err
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&user.ID, &user.Name, &user.IsActive, &user.TeamID)
+	if err != nil {
+		return nil, translateError(err)
 	}
-	return user, err
+	return user, nil
 }
 
-func (r *UserRepository) GetByTeamID(teamID string) ([]*domain.User, error) {
+func (r *UserRepository) GetByTeamID(ctx context.Context, teamID string) ([]*domain.User, error) {
 	query := `SELECT id, name, is_active, team_id FROM users WHERE team_id = $1`
-	rows, err := r.db.Query(query, teamID)
+	rows, err := r.db.QueryContext(ctx, query, teamID)
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 	defer rows.Close()
 
@@ -41,18 +44,18 @@ func (r *UserRepository) GetByTeamID(teamID string) ([]*domain.User, error) {
 	for rows.Next() {
 		user := &domain.User{}
 		if err := rows.Scan(&user.ID, &user.Name, &user.IsActive, &user.TeamID); err != nil {
-			return nil, err
+			return nil, translateError(err)
 		}
 		users = append(users, user)
 	}
 	return users, rows.Err()
 }
 
-func (r *UserRepository) GetActiveByTeamID(teamID string) ([]*domain.User, error) {
+func (r *UserRepository) GetActiveByTeamID(ctx context.Context, teamID string) ([]*domain.User, error) {
 	query := `SELECT id, name, is_active, team_id FROM users WHERE team_id = $1 AND is_active = true`
-	rows, err := r.db.Query(query, teamID)
+	rows, err := r.db.QueryContext(ctx, query, teamID)
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 	defer rows.Close()
 
@@ -60,38 +63,38 @@ func (r *UserRepository) GetActiveByTeamID(teamID string) ([]*domain.User, error
 	for rows.Next() {
 		user := &domain.User{}
 		if err := rows.Scan(&user.ID, &user.Name, &user.IsActive, &user.TeamID); err != nil {
-			return nil, err
+			return nil, translateError(err)
 		}
 		users = append(users, user)
 	}
 	return users, rows.Err()
 }
 
-func (r *UserRepository) Update(user *domain.User) error {
+func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
 	query := `UPDATE users SET name = $2, is_active = $3, team_id = $4 WHERE id = $1`
-	_, err := r.db.Exec(query, user.ID, user.Name, user.IsActive, user.TeamID)
-	return err
+	_, err := r.db.ExecContext(ctx, query, user.ID, user.Name, user.IsActive, user.TeamID)
+	return translateError(err)
 }
 
-func (r *UserRepository) DeactivateUsers(teamID string, userIDs []string) error {
+func (r *UserRepository) DeactivateUsers(ctx context.Context, teamID string, userIDs []string) error {
 	if len(userIDs) == 0 {
 		return nil
 	}
 
 	query := `UPDATE users SET is_active = false WHERE team_id = $1 AND id = ANY($2)`
-	_, err := r.db.Exec(query, teamID, userIDs)
-	return err
+	_, err := r.db.ExecContext(ctx, query, teamID, pq.Array(userIDs))
+	return translateError(err)
 }
 
-func (r *UserRepository) GetByIDs(ids []string) ([]*domain.User, error) {
+func (r *UserRepository) GetByIDs(ctx context.Context, ids []string) ([]*domain.User, error) {
 	if len(ids) == 0 {
 		return []*domain.User{}, nil
 	}
 
 	query := `SELECT id, name, is_active, team_id FROM users WHERE id = ANY($1)`
-	rows, err := r.db.Query(query, ids)
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids))
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 	defer rows.Close()
 
@@ -99,9 +102,9 @@ func (r *UserRepository) GetByIDs(ids []string) ([]*domain.User, error) {
 	for rows.Next() {
 		user := &domain.User{}
 		if err := rows.Scan(&user.ID, &user.Name, &user.IsActive, &user.TeamID); err != nil {
-			return nil, err
+			return nil, translateError(err)
 		}
 		users = append(users, user)
 	}
 	return users, rows.Err()
-}
\ No newline at end of file
+}
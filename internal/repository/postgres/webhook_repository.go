@@ -0,0 +1,200 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/danonenka/PR-service/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+const webhookSubscriptionColumns = `id, url, secret, COALESCE(team_id, ''), event_types, created_at`
+
+func scanWebhookSubscription(row rowScanner) (*domain.WebhookSubscription, error) {
+	subscription := &domain.WebhookSubscription{}
+	var eventTypes pq.StringArray
+	if err := row.Scan(&subscription.ID, &subscription.URL, &subscription.Secret, &subscription.TeamID, &eventTypes, &subscription.CreatedAt); err != nil {
+		return nil, translateError(err)
+	}
+	subscription.EventTypes = make([]domain.WebhookEventType, 0, len(eventTypes))
+	for _, t := range eventTypes {
+		subscription.EventTypes = append(subscription.EventTypes, domain.WebhookEventType(t))
+	}
+	return subscription, nil
+}
+
+func scanWebhookSubscriptions(rows *sql.Rows) ([]*domain.WebhookSubscription, error) {
+	defer rows.Close()
+
+	subscriptions := make([]*domain.WebhookSubscription, 0)
+	for rows.Next() {
+		subscription, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	return subscriptions, rows.Err()
+}
+
+type WebhookSubscriptionRepository struct {
+	db querier
+}
+
+func NewWebhookSubscriptionRepository(db *sql.DB) *WebhookSubscriptionRepository {
+	return &WebhookSubscriptionRepository{db: logQueries(db)}
+}
+
+func (r *WebhookSubscriptionRepository) Create(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	eventTypes := make([]string, 0, len(subscription.EventTypes))
+	for _, t := range subscription.EventTypes {
+		eventTypes = append(eventTypes, string(t))
+	}
+	query := `
+		INSERT INTO webhook_subscriptions (id, url, secret, team_id, event_types)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+		RETURNING created_at
+	`
+	err := r.db.QueryRowContext(ctx, query, subscription.ID, subscription.URL, subscription.Secret, subscription.TeamID, pq.Array(eventTypes)).
+		Scan(&subscription.CreatedAt)
+	return translateError(err)
+}
+
+func (r *WebhookSubscriptionRepository) GetByID(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE id = $1`
+	return scanWebhookSubscription(r.db.QueryRowContext(ctx, query, id))
+}
+
+func (r *WebhookSubscriptionRepository) GetAll(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions ORDER BY created_at, id`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return scanWebhookSubscriptions(rows)
+}
+
+func (r *WebhookSubscriptionRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return translateError(err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return translateError(err)
+	}
+	if affected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *WebhookSubscriptionRepository) GetMatching(ctx context.Context, teamID string, eventType domain.WebhookEventType) ([]*domain.WebhookSubscription, error) {
+	query := `
+		SELECT ` + webhookSubscriptionColumns + `
+		FROM webhook_subscriptions
+		WHERE (team_id IS NULL OR team_id = $1)
+			AND (cardinality(event_types) = 0 OR $2 = ANY(event_types))
+		ORDER BY id
+	`
+	rows, err := r.db.QueryContext(ctx, query, teamID, string(eventType))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return scanWebhookSubscriptions(rows)
+}
+
+const webhookDeliveryColumns = `id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+	COALESCE(last_status_code, 0), COALESCE(last_error, ''), created_at, delivered_at`
+
+func scanWebhookDeliveries(rows *sql.Rows) ([]*domain.WebhookDelivery, error) {
+	defer rows.Close()
+
+	deliveries := make([]*domain.WebhookDelivery, 0)
+	for rows.Next() {
+		delivery := &domain.WebhookDelivery{}
+		var deliveredAt sql.NullTime
+		if err := rows.Scan(&delivery.ID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType, &delivery.Payload,
+			&delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt, &delivery.LastStatusCode, &delivery.LastError,
+			&delivery.CreatedAt, &deliveredAt); err != nil {
+			return nil, translateError(err)
+		}
+		if deliveredAt.Valid {
+			delivery.DeliveredAt = &deliveredAt.Time
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+type WebhookDeliveryRepository struct {
+	db querier
+}
+
+func NewWebhookDeliveryRepository(db *sql.DB) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{db: logQueries(db)}
+}
+
+func (r *WebhookDeliveryRepository) Create(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	if delivery.Status == "" {
+		delivery.Status = domain.WebhookDeliveryPending
+	}
+	query := `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, status)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, next_attempt_at, created_at
+	`
+	err := r.db.QueryRowContext(ctx, query, delivery.SubscriptionID, delivery.EventID, delivery.EventType, string(delivery.Payload), delivery.Status).
+		Scan(&delivery.ID, &delivery.NextAttemptAt, &delivery.CreatedAt)
+	return translateError(err)
+}
+
+func (r *WebhookDeliveryRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*domain.WebhookDelivery, error) {
+	// SKIP LOCKED позволяет нескольким экземплярам сервиса разбирать
+	// outbox, не дожидаясь друг друга
+	query := `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'PENDING' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at, id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + webhookDeliveryColumns
+	rows, err := r.db.QueryContext(ctx, query, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return scanWebhookDeliveries(rows)
+}
+
+func (r *WebhookDeliveryRepository) SaveAttempt(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, next_attempt_at = $4,
+			last_status_code = NULLIF($5, 0), last_error = NULLIF($6, ''), delivered_at = $7
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, delivery.ID, delivery.Status, delivery.Attempts, delivery.NextAttemptAt,
+		delivery.LastStatusCode, delivery.LastError, delivery.DeliveredAt)
+	return translateError(err)
+}
+
+func (r *WebhookDeliveryRepository) ListBySubscription(ctx context.Context, subscriptionID string, status domain.WebhookDeliveryStatus, limit int) ([]*domain.WebhookDelivery, error) {
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries
+		WHERE subscription_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY id DESC
+		LIMIT $3
+	`
+	rows, err := r.db.QueryContext(ctx, query, subscriptionID, string(status), limit)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return scanWebhookDeliveries(rows)
+}
@@ -0,0 +1,631 @@
+// Package repositorytest содержит контрактные тесты репозиториев domain.
+// Любая реализация хранилища должна проходить их одинаково: тесты
+// проверяют то, на что опираются usecase, а не детали хранения.
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/danonenka/PR-service/internal/domain"
+)
+
+// Repositories — набор проверяемых репозиториев над одним хранилищем.
+type Repositories struct {
+	Users                domain.UserRepository
+	Teams                domain.TeamRepository
+	PullRequests         domain.PullRequestRepository
+	Assignments          domain.ReviewerAssignmentRepository
+	Events               domain.PREventRepository
+	WebhookSubscriptions domain.WebhookSubscriptionRepository
+	WebhookDeliveries    domain.WebhookDeliveryRepository
+	Identities           domain.IdentityRepository
+	TxManager            domain.TxManager
+}
+
+// Run прогоняет контракт. newRepositories вызывается для каждого подтеста
+// и должна возвращать репозитории над пустым хранилищем; очистку она
+// регистрирует сама через t.Cleanup.
+func Run(t *testing.T, newRepositories func(t *testing.T) Repositories) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, r Repositories)
+	}{
+		{"Teams", testTeams},
+		{"Users", testUsers},
+		{"GetByIDs", testGetByIDs},
+		{"DeactivateUsers", testDeactivateUsers},
+		{"PullRequests", testPullRequests},
+		{"Assignments", testAssignments},
+		{"Events", testEvents},
+		{"Webhooks", testWebhooks},
+		{"Identities", testIdentities},
+		{"CascadeDeleteTeam", testCascadeDeleteTeam},
+		{"CascadeDeleteSubscription", testCascadeDeleteSubscription},
+		{"Transactions", testTransactions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepositories(t))
+		})
+	}
+}
+
+// createdAt — фиксированное время с точностью до микросекунд, чтобы оно
+// без потерь проходило через timestamptz.
+var createdAt = time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+func newTeam(id string) *domain.Team {
+	return &domain.Team{
+		ID:               id,
+		Name:             "team " + id,
+		ReviewerStrategy: domain.ReviewerStrategyRandom,
+		MinReviewers:     1,
+		MaxReviewers:     2,
+	}
+}
+
+func newPR(id, authorID string) *domain.PullRequest {
+	return &domain.PullRequest{
+		ID:        id,
+		Title:     "PR " + id,
+		AuthorID:  authorID,
+		Status:    domain.PRStatusOpen,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+}
+
+// seedTeam создаёт команду с активными участниками userIDs.
+func seedTeam(t *testing.T, r Repositories, teamID string, userIDs ...string) {
+	t.Helper()
+	ctx := context.Background()
+	if err := r.Teams.Create(ctx, newTeam(teamID)); err != nil {
+		t.Fatalf("create team %s: %v", teamID, err)
+	}
+	for _, id := range userIDs {
+		user := &domain.User{ID: id, Name: "user " + id, IsActive: true, TeamID: teamID}
+		if err := r.Users.Create(ctx, user); err != nil {
+			t.Fatalf("create user %s: %v", id, err)
+		}
+	}
+}
+
+func seedPR(t *testing.T, r Repositories, prID, authorID string, reviewerIDs ...string) {
+	t.Helper()
+	ctx := context.Background()
+	if err := r.PullRequests.Create(ctx, newPR(prID, authorID)); err != nil {
+		t.Fatalf("create PR %s: %v", prID, err)
+	}
+	for _, reviewerID := range reviewerIDs {
+		assignment := &domain.ReviewerAssignment{PRID: prID, ReviewerID: reviewerID}
+		if err := r.Assignments.Create(ctx, assignment); err != nil {
+			t.Fatalf("assign %s to %s: %v", reviewerID, prID, err)
+		}
+	}
+}
+
+func expectError(t *testing.T, what string, err, target error) {
+	t.Helper()
+	if !errors.Is(err, target) {
+		t.Errorf("%s: expected %v, got %v", what, target, err)
+	}
+}
+
+// userIDs возвращает отсортированные id: порядок строк в выборках по
+// команде контрактом не задан.
+func userIDs(users []*domain.User) []string {
+	ids := make([]string, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func expectIDs(t *testing.T, what string, got, want []string) {
+	t.Helper()
+	if !slices.Equal(got, want) {
+		t.Errorf("%s: expected %v, got %v", what, want, got)
+	}
+}
+
+func testTeams(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	team := newTeam("t1")
+	team.RequiredApprovals = 1
+	if err := r.Teams.Create(ctx, team); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	got, err := r.Teams.GetByID(ctx, "t1")
+	if err != nil {
+		t.Fatalf("get by id: %v", err)
+	}
+	if *got != *team {
+		t.Errorf("expected %+v, got %+v", *team, *got)
+	}
+	if got, err := r.Teams.GetByName(ctx, team.Name); err != nil || got.ID != "t1" {
+		t.Errorf("get by name: %+v, %v", got, err)
+	}
+
+	_, err = r.Teams.GetByID(ctx, "missing")
+	expectError(t, "get missing team", err, domain.ErrNotFound)
+	_, err = r.Teams.GetByName(ctx, "missing")
+	expectError(t, "get missing team by name", err, domain.ErrNotFound)
+
+	expectError(t, "duplicate id", r.Teams.Create(ctx, newTeam("t1")), domain.ErrConflict)
+	duplicateName := newTeam("t2")
+	duplicateName.Name = team.Name
+	expectError(t, "duplicate name", r.Teams.Create(ctx, duplicateName), domain.ErrConflict)
+	invalid := newTeam("t3")
+	invalid.MinReviewers, invalid.MaxReviewers = 3, 2
+	expectError(t, "invalid limits", r.Teams.Create(ctx, invalid), domain.ErrInvalidArgument)
+
+	team.ReviewerStrategy = domain.ReviewerStrategyRoundRobin
+	team.MaxReviewers = 3
+	if err := r.Teams.Update(ctx, team); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if got, _ := r.Teams.GetByID(ctx, "t1"); got == nil || *got != *team {
+		t.Errorf("update not persisted: %+v", got)
+	}
+
+	seedTeam(t, r, "t2")
+	teams, err := r.Teams.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("get all: %v", err)
+	}
+	if len(teams) != 2 {
+		t.Errorf("expected 2 teams, got %d", len(teams))
+	}
+}
+
+func testUsers(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	orphan := &domain.User{ID: "u0", Name: "orphan", IsActive: true, TeamID: "missing"}
+	expectError(t, "user of missing team", r.Users.Create(ctx, orphan), domain.ErrNotFound)
+
+	seedTeam(t, r, "t1", "u1", "u2")
+	seedTeam(t, r, "t2", "u3")
+	duplicate := &domain.User{ID: "u1", Name: "again", IsActive: true, TeamID: "t2"}
+	expectError(t, "duplicate user", r.Users.Create(ctx, duplicate), domain.ErrConflict)
+
+	user, err := r.Users.GetByID(ctx, "u1")
+	if err != nil {
+		t.Fatalf("get by id: %v", err)
+	}
+	if user.Name != "user u1" || !user.IsActive || user.TeamID != "t1" {
+		t.Errorf("unexpected user: %+v", user)
+	}
+	_, err = r.Users.GetByID(ctx, "missing")
+	expectError(t, "get missing user", err, domain.ErrNotFound)
+
+	user.IsActive = false
+	user.Name = "renamed"
+	if err := r.Users.Update(ctx, user); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if got, _ := r.Users.GetByID(ctx, "u1"); got == nil || *got != *user {
+		t.Errorf("update not persisted: %+v", got)
+	}
+
+	users, err := r.Users.GetByTeamID(ctx, "t1")
+	if err != nil {
+		t.Fatalf("get by team: %v", err)
+	}
+	expectIDs(t, "team members", userIDs(users), []string{"u1", "u2"})
+	users, err = r.Users.GetActiveByTeamID(ctx, "t1")
+	if err != nil {
+		t.Fatalf("get active by team: %v", err)
+	}
+	expectIDs(t, "active team members", userIDs(users), []string{"u2"})
+	users, err = r.Users.GetByTeamID(ctx, "missing")
+	if err != nil || users == nil || len(users) != 0 {
+		t.Errorf("members of missing team: %v, %v", users, err)
+	}
+}
+
+func testGetByIDs(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	seedTeam(t, r, "t1", "u1", "u2", "u3")
+
+	for name, ids := range map[string][]string{"nil": nil, "empty": {}} {
+		users, err := r.Users.GetByIDs(ctx, ids)
+		if err != nil {
+			t.Fatalf("%s ids: %v", name, err)
+		}
+		if users == nil || len(users) != 0 {
+			t.Errorf("%s ids: expected empty non-nil slice, got %#v", name, users)
+		}
+	}
+
+	users, err := r.Users.GetByIDs(ctx, []string{"u3", "missing", "u1", "u1"})
+	if err != nil {
+		t.Fatalf("get by ids: %v", err)
+	}
+	expectIDs(t, "users by ids", userIDs(users), []string{"u1", "u3"})
+}
+
+func testDeactivateUsers(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	seedTeam(t, r, "t1", "u1", "u2", "u3")
+	seedTeam(t, r, "t2", "u4")
+
+	if err := r.Users.DeactivateUsers(ctx, "t1", nil); err != nil {
+		t.Fatalf("deactivate nobody: %v", err)
+	}
+	// u4 из другой команды: деактивация ограничена командой
+	if err := r.Users.DeactivateUsers(ctx, "t1", []string{"u1", "u3", "u4", "missing"}); err != nil {
+		t.Fatalf("deactivate: %v", err)
+	}
+
+	users, err := r.Users.GetActiveByTeamID(ctx, "t1")
+	if err != nil {
+		t.Fatalf("get active: %v", err)
+	}
+	expectIDs(t, "active in t1", userIDs(users), []string{"u2"})
+	if user, err := r.Users.GetByID(ctx, "u4"); err != nil || !user.IsActive {
+		t.Errorf("user of another team must stay active: %+v, %v", user, err)
+	}
+}
+
+func testPullRequests(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	expectError(t, "PR of missing author", r.PullRequests.Create(ctx, newPR("pr0", "missing")), domain.ErrNotFound)
+
+	seedTeam(t, r, "t1", "u1", "u2", "u3")
+	pr := newPR("pr1", "u1")
+	if err := r.PullRequests.Create(ctx, pr); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if pr.Version != 1 {
+		t.Errorf("expected version 1 after create, got %d", pr.Version)
+	}
+	expectError(t, "duplicate PR", r.PullRequests.Create(ctx, newPR("pr1", "u2")), domain.ErrConflict)
+	invalid := newPR("pr2", "u1")
+	invalid.Status = "REVIEWING"
+	expectError(t, "invalid status", r.PullRequests.Create(ctx, invalid), domain.ErrInvalidArgument)
+
+	got, err := r.PullRequests.GetByID(ctx, "pr1")
+	if err != nil {
+		t.Fatalf("get by id: %v", err)
+	}
+	if got.Title != pr.Title || got.AuthorID != "u1" || got.Status != domain.PRStatusOpen ||
+		!got.CreatedAt.Equal(createdAt) || got.MergedAt != nil || got.Version != 1 {
+		t.Errorf("unexpected PR: %+v", got)
+	}
+	_, err = r.PullRequests.GetByID(ctx, "missing")
+	expectError(t, "get missing PR", err, domain.ErrNotFound)
+
+	mergedAt := createdAt.Add(time.Hour)
+	got.Status = domain.PRStatusMerged
+	got.MergedAt = &mergedAt
+	got.UpdatedAt = mergedAt
+	if err := r.PullRequests.Update(ctx, got); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if got.Version != 2 {
+		t.Errorf("expected version 2 after update, got %d", got.Version)
+	}
+	// pr всё ещё держит версию 1
+	pr.Title = "stale"
+	expectError(t, "stale update", r.PullRequests.Update(ctx, pr), domain.ErrConcurrentModification)
+	expectError(t, "update missing PR", r.PullRequests.Update(ctx, newPR("missing", "u1")), domain.ErrConcurrentModification)
+
+	merged, err := r.PullRequests.GetByID(ctx, "pr1")
+	if err != nil {
+		t.Fatalf("get merged: %v", err)
+	}
+	if merged.Title != "PR pr1" || merged.Status != domain.PRStatusMerged ||
+		merged.MergedAt == nil || !merged.MergedAt.Equal(mergedAt) || merged.Version != 2 {
+		t.Errorf("unexpected merged PR: %+v", merged)
+	}
+
+	seedPR(t, r, "pr3", "u1", "u3", "u2")
+	prs, err := r.PullRequests.GetByAuthorID(ctx, "u1")
+	if err != nil {
+		t.Fatalf("get by author: %v", err)
+	}
+	if len(prs) != 2 {
+		t.Fatalf("expected 2 PRs of u1, got %d", len(prs))
+	}
+	for _, pr := range prs {
+		if pr.ID == "pr3" {
+			reviewers := slices.Sorted(slices.Values(pr.ReviewerIDs))
+			expectIDs(t, "reviewers of pr3", reviewers, []string{"u2", "u3"})
+		}
+	}
+	prs, err = r.PullRequests.GetByReviewerID(ctx, "u2")
+	if err != nil || len(prs) != 1 || prs[0].ID != "pr3" {
+		t.Errorf("get by reviewer: %v, %v", prs, err)
+	}
+	prs, err = r.PullRequests.GetOpenByReviewerIDs(ctx, nil)
+	if err != nil || len(prs) != 0 {
+		t.Errorf("open PRs of nobody: %v, %v", prs, err)
+	}
+}
+
+func testAssignments(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	seedTeam(t, r, "t1", "u1", "u2", "u3")
+	seedPR(t, r, "pr1", "u1", "u2")
+
+	missingPR := &domain.ReviewerAssignment{PRID: "missing", ReviewerID: "u2"}
+	expectError(t, "assignment to missing PR", r.Assignments.Create(ctx, missingPR), domain.ErrNotFound)
+	missingReviewer := &domain.ReviewerAssignment{PRID: "pr1", ReviewerID: "missing"}
+	expectError(t, "missing reviewer", r.Assignments.Create(ctx, missingReviewer), domain.ErrNotFound)
+	duplicate := &domain.ReviewerAssignment{PRID: "pr1", ReviewerID: "u2"}
+	expectError(t, "duplicate assignment", r.Assignments.Create(ctx, duplicate), domain.ErrConflict)
+
+	assignments, err := r.Assignments.GetByPRID(ctx, "pr1")
+	if err != nil {
+		t.Fatalf("get by PR: %v", err)
+	}
+	if len(assignments) != 1 || assignments[0].ReviewerID != "u2" ||
+		assignments[0].State != domain.ReviewStatePending || assignments[0].AssignedAt.IsZero() {
+		t.Fatalf("unexpected assignments: %+v", assignments)
+	}
+
+	reviewedAt := createdAt.Add(time.Hour)
+	approved := assignments[0]
+	approved.State = domain.ReviewStateApproved
+	approved.ReviewedAt = &reviewedAt
+	if err := r.Assignments.UpdateState(ctx, approved); err != nil {
+		t.Fatalf("update state: %v", err)
+	}
+	assignments, err = r.Assignments.GetByReviewerID(ctx, "u2")
+	if err != nil || len(assignments) != 1 {
+		t.Fatalf("get by reviewer: %v, %v", assignments, err)
+	}
+	if assignments[0].State != domain.ReviewStateApproved ||
+		assignments[0].ReviewedAt == nil || !assignments[0].ReviewedAt.Equal(reviewedAt) {
+		t.Errorf("state not persisted: %+v", assignments[0])
+	}
+	missing := &domain.ReviewerAssignment{PRID: "pr1", ReviewerID: "u3", State: domain.ReviewStateApproved}
+	expectError(t, "update missing assignment", r.Assignments.UpdateState(ctx, missing), domain.ErrNotFound)
+
+	counts, err := r.Assignments.CountOpenByReviewerIDs(ctx, []string{"u2", "u3"})
+	if err != nil {
+		t.Fatalf("count open: %v", err)
+	}
+	if len(counts) != 1 || counts["u2"] != 1 {
+		t.Errorf("unexpected open counts: %v", counts)
+	}
+
+	if err := r.Assignments.Delete(ctx, "pr1", "u2"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	assignments, err = r.Assignments.GetByPRID(ctx, "pr1")
+	if err != nil || len(assignments) != 0 {
+		t.Errorf("assignments after delete: %v, %v", assignments, err)
+	}
+}
+
+func testEvents(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	first := &domain.PREvent{PRID: "pr1", Type: domain.PREventCreated, ActorID: "u1"}
+	second := &domain.PREvent{PRID: "pr1", Type: domain.PREventStatusChanged, ActorID: "u1"}
+	review := &domain.PREvent{PRID: "pr1", Type: domain.PREventReviewSubmitted, ActorID: "u2", ReviewerID: "u2", ReviewState: domain.ReviewStateApproved}
+	for _, event := range []*domain.PREvent{first, second, review} {
+		if err := r.Events.Create(ctx, event); err != nil {
+			t.Fatalf("create event: %v", err)
+		}
+	}
+	if first.ID == 0 || second.ID <= first.ID || first.CreatedAt.IsZero() {
+		t.Errorf("unexpected ids or time: %+v, %+v", first, second)
+	}
+
+	events, err := r.Events.GetByPRID(ctx, "pr1")
+	if err != nil {
+		t.Fatalf("get by PR: %v", err)
+	}
+	if len(events) != 3 || events[0].ID != first.ID || events[1].Type != domain.PREventStatusChanged ||
+		events[2].ReviewState != domain.ReviewStateApproved {
+		t.Errorf("unexpected events: %+v", events)
+	}
+	events, err = r.Events.GetByPRID(ctx, "missing")
+	if err != nil || events == nil || len(events) != 0 {
+		t.Errorf("events of missing PR: %v, %v", events, err)
+	}
+}
+
+func testWebhooks(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	seedTeam(t, r, "t1")
+
+	orphan := &domain.WebhookSubscription{ID: "w0", URL: "http://hooks", Secret: "s", TeamID: "missing"}
+	expectError(t, "subscription of missing team", r.WebhookSubscriptions.Create(ctx, orphan), domain.ErrNotFound)
+
+	subscription := &domain.WebhookSubscription{
+		ID:         "w1",
+		URL:        "http://hooks/w1",
+		Secret:     "secret",
+		TeamID:     "t1",
+		EventTypes: []domain.WebhookEventType{domain.WebhookEventPRMerged},
+	}
+	if err := r.WebhookSubscriptions.Create(ctx, subscription); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if subscription.CreatedAt.IsZero() {
+		t.Error("created_at is not set")
+	}
+	again := &domain.WebhookSubscription{ID: "w1", URL: "http://hooks", Secret: "s"}
+	expectError(t, "duplicate subscription", r.WebhookSubscriptions.Create(ctx, again), domain.ErrConflict)
+
+	got, err := r.WebhookSubscriptions.GetByID(ctx, "w1")
+	if err != nil {
+		t.Fatalf("get by id: %v", err)
+	}
+	if got.URL != subscription.URL || got.Secret != "secret" || got.TeamID != "t1" ||
+		!slices.Equal(got.EventTypes, subscription.EventTypes) {
+		t.Errorf("unexpected subscription: %+v", got)
+	}
+	_, err = r.WebhookSubscriptions.GetByID(ctx, "missing")
+	expectError(t, "get missing subscription", err, domain.ErrNotFound)
+	expectError(t, "delete missing subscription", r.WebhookSubscriptions.Delete(ctx, "missing"), domain.ErrNotFound)
+
+	matching, err := r.WebhookSubscriptions.GetMatching(ctx, "t1", domain.WebhookEventReviewerAssigned)
+	if err != nil || len(matching) != 0 {
+		t.Errorf("matching other event: %v, %v", matching, err)
+	}
+	matching, err = r.WebhookSubscriptions.GetMatching(ctx, "t1", domain.WebhookEventPRMerged)
+	if err != nil || len(matching) != 1 {
+		t.Errorf("matching event: %v, %v", matching, err)
+	}
+
+	delivery := &domain.WebhookDelivery{SubscriptionID: "missing", EventID: "e0", EventType: domain.WebhookEventPRMerged, Payload: []byte(`{}`)}
+	expectError(t, "delivery of missing subscription", r.WebhookDeliveries.Create(ctx, delivery), domain.ErrNotFound)
+}
+
+func testIdentities(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	orphan := &domain.IdentityMapping{Provider: domain.IdentityProviderGitHub, Login: "ghost", UserID: "missing"}
+	expectError(t, "mapping of missing user", r.Identities.Upsert(ctx, orphan), domain.ErrNotFound)
+
+	seedTeam(t, r, "t1", "u1", "u2")
+	mapping := &domain.IdentityMapping{Provider: domain.IdentityProviderGitHub, Login: "Octocat", UserID: "u1"}
+	if err := r.Identities.Upsert(ctx, mapping); err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+	mapping = &domain.IdentityMapping{Provider: domain.IdentityProviderGitHub, Login: "octocat", UserID: "u2"}
+	if err := r.Identities.Upsert(ctx, mapping); err != nil {
+		t.Fatalf("rebind: %v", err)
+	}
+	if userID, err := r.Identities.GetUserID(ctx, domain.IdentityProviderGitHub, "OCTOCAT"); err != nil || userID != "u2" {
+		t.Errorf("get user id: %q, %v", userID, err)
+	}
+	_, err := r.Identities.GetUserID(ctx, domain.IdentityProviderGitHub, "missing")
+	expectError(t, "get missing login", err, domain.ErrNotFound)
+}
+
+// testCascadeDeleteTeam проверяет, что удаление команды уносит всё, что
+// ссылается на её участников, и не трогает чужие данные.
+func testCascadeDeleteTeam(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	seedTeam(t, r, "t1", "u1", "u2")
+	seedTeam(t, r, "t2", "u3", "u4")
+	seedPR(t, r, "pr1", "u1", "u2")
+	seedPR(t, r, "pr2", "u3", "u4", "u1")
+	mapping := &domain.IdentityMapping{Provider: domain.IdentityProviderGitHub, Login: "u1", UserID: "u1"}
+	if err := r.Identities.Upsert(ctx, mapping); err != nil {
+		t.Fatalf("upsert identity: %v", err)
+	}
+	for _, subscription := range []*domain.WebhookSubscription{
+		{ID: "w1", URL: "http://hooks/w1", Secret: "s", TeamID: "t1"},
+		{ID: "w2", URL: "http://hooks/w2", Secret: "s"},
+	} {
+		if err := r.WebhookSubscriptions.Create(ctx, subscription); err != nil {
+			t.Fatalf("create subscription: %v", err)
+		}
+	}
+
+	if err := r.Teams.Delete(ctx, "t1"); err != nil {
+		t.Fatalf("delete team: %v", err)
+	}
+
+	_, err := r.Teams.GetByID(ctx, "t1")
+	expectError(t, "deleted team", err, domain.ErrNotFound)
+	_, err = r.Users.GetByID(ctx, "u1")
+	expectError(t, "member of deleted team", err, domain.ErrNotFound)
+	_, err = r.PullRequests.GetByID(ctx, "pr1")
+	expectError(t, "PR of deleted member", err, domain.ErrNotFound)
+	_, err = r.Identities.GetUserID(ctx, domain.IdentityProviderGitHub, "u1")
+	expectError(t, "identity of deleted member", err, domain.ErrNotFound)
+	_, err = r.WebhookSubscriptions.GetByID(ctx, "w1")
+	expectError(t, "subscription of deleted team", err, domain.ErrNotFound)
+
+	if _, err := r.WebhookSubscriptions.GetByID(ctx, "w2"); err != nil {
+		t.Errorf("global subscription must survive: %v", err)
+	}
+	assignments, err := r.Assignments.GetByReviewerID(ctx, "u1")
+	if err != nil || len(assignments) != 0 {
+		t.Errorf("assignments of deleted member: %v, %v", assignments, err)
+	}
+	assignments, err = r.Assignments.GetByPRID(ctx, "pr2")
+	if err != nil || len(assignments) != 1 || assignments[0].ReviewerID != "u4" {
+		t.Errorf("assignments of surviving PR: %+v, %v", assignments, err)
+	}
+	users, err := r.Users.GetByTeamID(ctx, "t2")
+	if err != nil {
+		t.Fatalf("get surviving team: %v", err)
+	}
+	expectIDs(t, "surviving team", userIDs(users), []string{"u3", "u4"})
+}
+
+func testCascadeDeleteSubscription(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	for _, id := range []string{"w1", "w2"} {
+		subscription := &domain.WebhookSubscription{ID: id, URL: "http://hooks/" + id, Secret: "s"}
+		if err := r.WebhookSubscriptions.Create(ctx, subscription); err != nil {
+			t.Fatalf("create subscription: %v", err)
+		}
+		delivery := &domain.WebhookDelivery{SubscriptionID: id, EventID: "e-" + id, EventType: domain.WebhookEventPRMerged, Payload: []byte(`{}`)}
+		if err := r.WebhookDeliveries.Create(ctx, delivery); err != nil {
+			t.Fatalf("create delivery: %v", err)
+		}
+	}
+
+	if err := r.WebhookSubscriptions.Delete(ctx, "w1"); err != nil {
+		t.Fatalf("delete subscription: %v", err)
+	}
+
+	// Доставки удалённой подписки не должны уйти диспетчеру
+	due, err := r.WebhookDeliveries.ClaimDue(ctx, time.Now().Add(time.Hour), time.Minute, 10)
+	if err != nil {
+		t.Fatalf("claim due: %v", err)
+	}
+	if len(due) != 1 || due[0].SubscriptionID != "w2" {
+		t.Errorf("unexpected due deliveries: %+v", due)
+	}
+	deliveries, err := r.WebhookDeliveries.ListBySubscription(ctx, "w1", "", 10)
+	if err != nil || len(deliveries) != 0 {
+		t.Errorf("deliveries of deleted subscription: %v, %v", deliveries, err)
+	}
+}
+
+func testTransactions(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	errAbort := errors.New("abort")
+	err := r.TxManager.WithinTx(ctx, func(uow domain.UnitOfWork) error {
+		if err := uow.Teams().Create(ctx, newTeam("t1")); err != nil {
+			return err
+		}
+		user := &domain.User{ID: "u1", Name: "user u1", IsActive: true, TeamID: "t1"}
+		if err := uow.Users().Create(ctx, user); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	expectError(t, "aborted tx", err, errAbort)
+	_, err = r.Teams.GetByID(ctx, "t1")
+	expectError(t, "team after rollback", err, domain.ErrNotFound)
+	_, err = r.Users.GetByID(ctx, "u1")
+	expectError(t, "user after rollback", err, domain.ErrNotFound)
+
+	err = r.TxManager.WithinTx(ctx, func(uow domain.UnitOfWork) error {
+		if err := uow.Teams().Create(ctx, newTeam("t1")); err != nil {
+			return err
+		}
+		return uow.PullRequests().Create(ctx, newPR("pr1", "missing"))
+	})
+	expectError(t, "tx with failed statement", err, domain.ErrNotFound)
+	_, err = r.Teams.GetByID(ctx, "t1")
+	expectError(t, "team after failed statement", err, domain.ErrNotFound)
+
+	err = r.TxManager.WithinTx(ctx, func(uow domain.UnitOfWork) error {
+		if err := uow.Teams().Create(ctx, newTeam("t1")); err != nil {
+			return err
+		}
+		return uow.Users().Create(ctx, &domain.User{ID: "u1", Name: "user u1", IsActive: true, TeamID: "t1"})
+	})
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if _, err := r.Users.GetByID(ctx, "u1"); err != nil {
+		t.Errorf("user after commit: %v", err)
+	}
+}
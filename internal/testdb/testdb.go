@@ -0,0 +1,78 @@
+// Package testdb даёт тестам базу из TEST_DATABASE_URL. Тесты разных
+// пакетов пересоздают в ней схему, а go test запускает пакеты параллельно,
+// поэтому каждый тест держит базу эксклюзивно до своего завершения.
+package testdb
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/danonenka/PR-service/internal/migration"
+	"github.com/danonenka/PR-service/migrations"
+
+	_ "github.com/lib/pq"
+)
+
+// lockID — ключ pg_advisory_lock, под которым тест владеет базой. Он
+// отличается от ключа migration.Migrator, чтобы не мешать миграциям.
+const lockID = 72_315_002
+
+// Open открывает TEST_DATABASE_URL и ждёт, пока базу отпустят тесты других
+// пакетов. Без переменной тест пропускается. Блокировка снимается и
+// соединения закрываются в Cleanup.
+func Open(tb testing.TB) *sql.DB {
+	tb.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		tb.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		tb.Fatalf("open db: %v", err)
+	}
+
+	// Сессионная блокировка живёт, пока открыто это соединение
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		db.Close()
+		tb.Fatalf("connect: %v", err)
+	}
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockID); err != nil {
+		conn.Close()
+		db.Close()
+		tb.Fatalf("lock test database: %v", err)
+	}
+	tb.Cleanup(func() {
+		_, _ = conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, lockID)
+		conn.Close()
+		db.Close()
+	})
+	return db
+}
+
+// Migrate пересоздаёт схему встроенными миграциями и откатывает её в
+// Cleanup.
+func Migrate(tb testing.TB, db *sql.DB) {
+	tb.Helper()
+
+	ctx := context.Background()
+	migrator, err := migration.NewMigrator(db, migrations.FS)
+	if err != nil {
+		tb.Fatalf("new migrator: %v", err)
+	}
+	if _, err := migrator.Goto(ctx, 0); err != nil {
+		tb.Fatalf("reset schema: %v", err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		tb.Fatalf("apply migrations: %v", err)
+	}
+	tb.Cleanup(func() {
+		if _, err := migrator.Goto(ctx, 0); err != nil {
+			tb.Errorf("tear down schema: %v", err)
+		}
+	})
+}
@@ -0,0 +1,17 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/danonenka/PR-service/internal/domain"
+)
+
+// notFound заменяет ErrNotFound репозитория ошибкой с именем сущности.
+// Остальные ошибки, например обрыв соединения, возвращаются как есть,
+// чтобы не превращать сбой базы в 404.
+func notFound(entity string, err error) error {
+	if errors.Is(err, domain.ErrNotFound) {
+		return &domain.NotFoundError{Entity: entity, Err: err}
+	}
+	return err
+}
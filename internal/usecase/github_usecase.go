@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/danonenka/PR-service/internal/domain"
+)
+
+var ErrUnknownLogin = errors.New("git host login is not mapped to a user")
+
+// GitHubResult — чем закончилась обработка входящего вебхука.
+type GitHubResult string
+
+const (
+	GitHubResultProcessed GitHubResult = "processed"
+	GitHubResultIgnored   GitHubResult = "ignored"
+	GitHubResultDuplicate GitHubResult = "duplicate"
+)
+
+// GitHubPullRequestEvent — поля события pull_request, которые нужны сервису.
+type GitHubPullRequestEvent struct {
+	Action      string `json:"action"`
+	Number      int    `json:"number"`
+	PullRequest struct {
+		Title  string `json:"title"`
+		Draft  bool   `json:"draft"`
+		Merged bool   `json:"merged"`
+		User   struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"pull_request"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	Sender struct {
+		Login string `json:"login"`
+	} `json:"sender"`
+}
+
+// PRID — идентификатор PR в сервисе: "<owner>/<repo>#<number>".
+func (e *GitHubPullRequestEvent) PRID() string {
+	return fmt.Sprintf("%s#%d", e.Repository.FullName, e.Number)
+}
+
+type GitHubUsecase struct {
+	identityRepo domain.IdentityRepository
+	deliveryRepo domain.InboundDeliveryRepository
+	userRepo     domain.UserRepository
+	prUsecase    *PRUsecase
+}
+
+func NewGitHubUsecase(
+	identityRepo domain.IdentityRepository,
+	deliveryRepo domain.InboundDeliveryRepository,
+	userRepo domain.UserRepository,
+	prUsecase *PRUsecase,
+) *GitHubUsecase {
+	return &GitHubUsecase{
+		identityRepo: identityRepo,
+		deliveryRepo: deliveryRepo,
+		userRepo:     userRepo,
+		prUsecase:    prUsecase,
+	}
+}
+
+// MapIdentity привязывает логин на Git-хостинге к пользователю сервиса.
+func (u *GitHubUsecase) MapIdentity(ctx context.Context, login, userID string) (*domain.IdentityMapping, error) {
+	if _, err := u.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, notFound("user", err)
+	}
+	mapping := &domain.IdentityMapping{
+		Provider: domain.IdentityProviderGitHub,
+		Login:    login,
+		UserID:   userID,
+	}
+	if err := u.identityRepo.Upsert(ctx, mapping); err != nil {
+		return nil, err
+	}
+	return mapping, nil
+}
+
+func (u *GitHubUsecase) ListIdentities(ctx context.Context) ([]*domain.IdentityMapping, error) {
+	return u.identityRepo.GetByProvider(ctx, domain.IdentityProviderGitHub)
+}
+
+// HandlePullRequestEvent применяет событие pull_request: opened создаёт PR,
+// closed мержит или закрывает его, reopened и ready_for_review переоткрывают
+// и выводят из черновика. Доставка с уже принятым deliveryID пропускается;
+// если обработка не удалась, доставка забывается, чтобы её повтор прошёл.
+func (u *GitHubUsecase) HandlePullRequestEvent(ctx context.Context, deliveryID string, event *GitHubPullRequestEvent) (GitHubResult, error) {
+	claimed, err := u.deliveryRepo.Claim(ctx, domain.IdentityProviderGitHub, deliveryID, "pull_request")
+	if err != nil {
+		return "", err
+	}
+	if !claimed {
+		return GitHubResultDuplicate, nil
+	}
+
+	result, err := u.applyPullRequestEvent(ctx, event)
+	if err != nil {
+		if releaseErr := u.deliveryRepo.Release(ctx, domain.IdentityProviderGitHub, deliveryID); releaseErr != nil {
+			return "", errors.Join(err, releaseErr)
+		}
+		return "", err
+	}
+	return result, nil
+}
+
+func (u *GitHubUsecase) applyPullRequestEvent(ctx context.Context, event *GitHubPullRequestEvent) (GitHubResult, error) {
+	prID := event.PRID()
+	actorID, err := u.actorID(ctx, event.Sender.Login)
+	if err != nil {
+		return "", err
+	}
+
+	switch event.Action {
+	case "opened":
+		authorID, err := u.identityRepo.GetUserID(ctx, domain.IdentityProviderGitHub, event.PullRequest.User.Login)
+		// Сбой базы — не повод отклонять доставку навсегда: пусть вернётся 500
+		// и GitHub повторит её
+		if errors.Is(err, domain.ErrNotFound) {
+			return "", fmt.Errorf("%w: %s", ErrUnknownLogin, event.PullRequest.User.Login)
+		}
+		if err != nil {
+			return "", err
+		}
+		pr := &domain.PullRequest{
+			ID:          prID,
+			Title:       truncate(event.PullRequest.Title, 255),
+			AuthorID:    authorID,
+			Status:      domain.PRStatusOpen,
+			ReviewerIDs: []string{},
+		}
+		if event.PullRequest.Draft {
+			pr.Status = domain.PRStatusDraft
+		}
+		err = u.prUsecase.CreatePR(ctx, pr, actorID)
+		// PR мог быть заведён раньше вручную
+		if errors.Is(err, domain.ErrPRExists) {
+			return GitHubResultIgnored, nil
+		}
+		return GitHubResultProcessed, err
+	case "closed":
+		if event.PullRequest.Merged {
+			return GitHubResultProcessed, u.prUsecase.MergePR(ctx, prID, actorID)
+		}
+		return GitHubResultProcessed, u.prUsecase.ClosePR(ctx, prID, actorID)
+	case "reopened":
+		return GitHubResultProcessed, u.prUsecase.ReopenPR(ctx, prID, actorID)
+	case "ready_for_review":
+		return GitHubResultProcessed, u.prUsecase.MarkReady(ctx, prID, actorID)
+	}
+	return GitHubResultIgnored, nil
+}
+
+// actorID — пользователь, от имени которого пишется журнал PR; для
+// неизвестного логина сохраняется сам логин с префиксом провайдера.
+func (u *GitHubUsecase) actorID(ctx context.Context, login string) (string, error) {
+	if login == "" {
+		return "", nil
+	}
+	userID, err := u.identityRepo.GetUserID(ctx, domain.IdentityProviderGitHub, login)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.IdentityProviderGitHub + ":" + strings.ToLower(login), nil
+	}
+	return userID, err
+}
+
+func truncate(s string, maxRunes int) string {
+	if utf8.RuneCountInString(s) <= maxRunes {
+		return s
+	}
+	return string([]rune(s)[:maxRunes])
+}
@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/danonenka/PR-service/internal/domain"
+	"github.com/danonenka/PR-service/internal/repository/memory"
+)
+
+// failingIdentityRepository имитирует недоступную базу соответствий логинов.
+type failingIdentityRepository struct {
+	domain.IdentityRepository
+	err error
+}
+
+func (r failingIdentityRepository) GetUserID(ctx context.Context, provider, login string) (string, error) {
+	return "", r.err
+}
+
+// Сбой базы не должен выглядеть как неизвестный логин: на 422 GitHub не
+// повторяет доставку.
+func TestGitHubIdentityLookupFailureIsNotUnknownLogin(t *testing.T) {
+	store := memory.NewStore()
+	outage := errors.New("connection refused")
+	github := NewGitHubUsecase(failingIdentityRepository{err: outage}, memory.NewInboundDeliveryRepository(store),
+		memory.NewUserRepository(store), nil)
+
+	event := &GitHubPullRequestEvent{Action: "opened", Number: 1}
+	event.PullRequest.User.Login = "octocat"
+	event.Repository.FullName = "acme/api"
+
+	_, err := github.HandlePullRequestEvent(context.Background(), "d-1", event)
+	if !errors.Is(err, outage) || errors.Is(err, ErrUnknownLogin) {
+		t.Fatalf("got %v, want the lookup error", err)
+	}
+}
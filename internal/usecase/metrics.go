@@ -0,0 +1,25 @@
+package usecase
+
+import "github.com/danonenka/PR-service/internal/domain"
+
+// Metrics получает доменные события после фиксации транзакции, чтобы
+// откаченные изменения не попадали в счётчики. Реализация для Prometheus —
+// internal/metrics; без SetMetrics события отбрасываются.
+type Metrics interface {
+	PRCreated()
+	ReviewersAssigned(reason domain.AssignmentReason, count int)
+	// Reassignment — замена или снятие ревьюера; outcome для ручного
+	// переназначения всегда OutcomeReassigned.
+	Reassignment(reason domain.AssignmentReason, outcome ReassignmentOutcome)
+	// PRUnderstaffed — у PR осталось меньше min_reviewers ревьюеров.
+	PRUnderstaffed(reason domain.AssignmentReason)
+	PRMerged()
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) PRCreated()                                                {}
+func (noopMetrics) ReviewersAssigned(domain.AssignmentReason, int)            {}
+func (noopMetrics) Reassignment(domain.AssignmentReason, ReassignmentOutcome) {}
+func (noopMetrics) PRUnderstaffed(domain.AssignmentReason)                    {}
+func (noopMetrics) PRMerged()                                                 {}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/danonenka/PR-service/internal/domain"
+)
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 200
+)
+
+var ErrInvalidCursor error = domain.NewValidationError("invalid cursor")
+
+// PRPage — страница списка PR. NextCursor пуст, если страница последняя.
+type PRPage struct {
+	Items      []*domain.PullRequest
+	NextCursor string
+}
+
+// encodeCursor превращает позицию последнего PR страницы в непрозрачную
+// для клиента строку. Поле и направление сортировки входят в курсор, чтобы
+// курсор от одной сортировки нельзя было применить к другой.
+func encodeCursor(pr *domain.PullRequest, sortBy domain.PRSortField, descending bool) string {
+	value := pr.CreatedAt
+	if sortBy == domain.PRSortUpdatedAt {
+		value = pr.UpdatedAt
+	}
+	raw := string(sortBy) + "|" + sortOrder(descending) + "|" + value.UTC().Format(time.RFC3339Nano) + "|" + pr.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string, sortBy domain.PRSortField, descending bool) (*domain.PRCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(raw), "|", 4)
+	if len(parts) != 4 || domain.PRSortField(parts[0]) != sortBy || parts[1] != sortOrder(descending) || parts[3] == "" {
+		return nil, ErrInvalidCursor
+	}
+	value, err := time.Parse(time.RFC3339Nano, parts[2])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &domain.PRCursor{Value: value, ID: parts[3]}, nil
+}
+
+func sortOrder(descending bool) string {
+	if descending {
+		return "desc"
+	}
+	return "asc"
+}
+
+func normalizeLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultPageLimit
+	}
+	if limit > MaxPageLimit {
+		return MaxPageLimit
+	}
+	return limit
+}
+
+// listPage запрашивает на один PR больше лимита, чтобы понять, есть ли
+// следующая страница, не делая отдельный COUNT.
+func listPage(ctx context.Context, repo domain.PullRequestRepository, filter domain.PRListFilter, cursor string) (*PRPage, error) {
+	if filter.SortBy == "" {
+		filter.SortBy = domain.PRSortCreatedAt
+	}
+	after, err := decodeCursor(cursor, filter.SortBy, filter.Descending)
+	if err != nil {
+		return nil, err
+	}
+	limit := normalizeLimit(filter.Limit)
+	filter.After = after
+	filter.Limit = limit + 1
+
+	prs, err := repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &PRPage{Items: prs}
+	if len(prs) > limit {
+		page.Items = prs[:limit]
+		page.NextCursor = encodeCursor(page.Items[limit-1], filter.SortBy, filter.Descending)
+	}
+	return page, nil
+}
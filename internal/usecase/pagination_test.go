@@ -0,0 +1,30 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/danonenka/PR-service/internal/domain"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	pr := &domain.PullRequest{ID: "pr-7", CreatedAt: time.Date(2025, 3, 1, 10, 0, 0, 123, time.UTC)}
+	cursor := encodeCursor(pr, domain.PRSortCreatedAt, true)
+
+	after, err := decodeCursor(cursor, domain.PRSortCreatedAt, true)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if after.ID != pr.ID || !after.Value.Equal(pr.CreatedAt) {
+		t.Errorf("unexpected cursor position: %+v", after)
+	}
+
+	// Курсор от другой сортировки дал бы не ту страницу
+	if _, err := decodeCursor(cursor, domain.PRSortCreatedAt, false); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("other order: expected ErrInvalidCursor, got %v", err)
+	}
+	if _, err := decodeCursor(cursor, domain.PRSortUpdatedAt, true); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("other field: expected ErrInvalidCursor, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/danonenka/PR-service/internal/domain"
+)
+
+func reviewerAssignedEvent(prID, reviewerID string, reason domain.AssignmentReason, actorID string) *domain.PREvent {
+	return &domain.PREvent{
+		PRID:       prID,
+		Type:       domain.PREventReviewerAssigned,
+		ActorID:    actorID,
+		ReviewerID: reviewerID,
+		Reason:     reason,
+	}
+}
+
+func reviewerRemovedEvent(prID, reviewerID string, reason domain.AssignmentReason, actorID string) *domain.PREvent {
+	return &domain.PREvent{
+		PRID:       prID,
+		Type:       domain.PREventReviewerRemoved,
+		ActorID:    actorID,
+		ReviewerID: reviewerID,
+		Reason:     reason,
+	}
+}
+
+func statusChangedEvent(prID string, from, to domain.PRStatus, actorID string) *domain.PREvent {
+	return &domain.PREvent{
+		PRID:       prID,
+		Type:       domain.PREventStatusChanged,
+		ActorID:    actorID,
+		FromStatus: from,
+		ToStatus:   to,
+	}
+}
+
+func reviewSubmittedEvent(prID, reviewerID string, state domain.ReviewState, actorID string) *domain.PREvent {
+	return &domain.PREvent{
+		PRID:        prID,
+		Type:        domain.PREventReviewSubmitted,
+		ActorID:     actorID,
+		ReviewerID:  reviewerID,
+		ReviewState: state,
+	}
+}
+
+// recordEvents пишет события в журнал в транзакции uow, чтобы журнал
+// не расходился с самими изменениями.
+func recordEvents(ctx context.Context, uow domain.UnitOfWork, events ...*domain.PREvent) error {
+	for _, event := range events {
+		if err := uow.Events().Create(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/danonenka/PR-service/internal/domain"
+)
+
+// PRAction — операция над PR, меняющая его статус.
+type PRAction string
+
+const (
+	PRActionMarkReady PRAction = "markReady"
+	PRActionMerge     PRAction = "merge"
+	PRActionClose     PRAction = "close"
+	PRActionReopen    PRAction = "reopen"
+)
+
+// prTransitions — допустимые переходы: действие -> статус до -> статус после.
+// Переход в статус, в котором PR уже находится, не считается ошибкой и
+// ничего не меняет, как и повторный merge.
+var prTransitions = map[PRAction]map[domain.PRStatus]domain.PRStatus{
+	PRActionMarkReady: {
+		domain.PRStatusDraft: domain.PRStatusOpen,
+	},
+	PRActionMerge: {
+		domain.PRStatusOpen: domain.PRStatusMerged,
+	},
+	PRActionClose: {
+		domain.PRStatusDraft: domain.PRStatusClosed,
+		domain.PRStatusOpen:  domain.PRStatusClosed,
+	},
+	PRActionReopen: {
+		domain.PRStatusClosed: domain.PRStatusOpen,
+	},
+}
+
+// prActionTargets — статус, в который ведёт действие; нужен, чтобы узнать
+// повторный вызов.
+var prActionTargets = map[PRAction]domain.PRStatus{
+	PRActionMarkReady: domain.PRStatusOpen,
+	PRActionMerge:     domain.PRStatusMerged,
+	PRActionClose:     domain.PRStatusClosed,
+	PRActionReopen:    domain.PRStatusOpen,
+}
+
+// TransitionError — действие недопустимо в текущем статусе PR.
+// errors.Is(err, domain.ErrInvalidTransition) для него истинно.
+type TransitionError struct {
+	Action PRAction
+	From   domain.PRStatus
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot %s PR in status %s", e.Action, e.From)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return domain.ErrInvalidTransition
+}
+
+// nextStatus возвращает статус после действия. changed == false, если PR
+// уже в целевом статусе и менять ничего не нужно.
+func nextStatus(action PRAction, from domain.PRStatus) (to domain.PRStatus, changed bool, err error) {
+	if prActionTargets[action] == from {
+		return from, false, nil
+	}
+	to, ok := prTransitions[action][from]
+	if !ok {
+		return from, false, &TransitionError{Action: action, From: from}
+	}
+	return to, true, nil
+}
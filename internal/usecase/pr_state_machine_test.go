@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/danonenka/PR-service/internal/domain"
+)
+
+func TestNextStatus(t *testing.T) {
+	tests := []struct {
+		action  PRAction
+		from    domain.PRStatus
+		to      domain.PRStatus
+		changed bool
+		invalid bool
+	}{
+		{action: PRActionMarkReady, from: domain.PRStatusDraft, to: domain.PRStatusOpen, changed: true},
+		{action: PRActionMarkReady, from: domain.PRStatusOpen, to: domain.PRStatusOpen},
+		{action: PRActionMarkReady, from: domain.PRStatusClosed, invalid: true},
+		{action: PRActionMarkReady, from: domain.PRStatusMerged, invalid: true},
+
+		{action: PRActionMerge, from: domain.PRStatusOpen, to: domain.PRStatusMerged, changed: true},
+		{action: PRActionMerge, from: domain.PRStatusMerged, to: domain.PRStatusMerged},
+		{action: PRActionMerge, from: domain.PRStatusDraft, invalid: true},
+		{action: PRActionMerge, from: domain.PRStatusClosed, invalid: true},
+
+		{action: PRActionClose, from: domain.PRStatusDraft, to: domain.PRStatusClosed, changed: true},
+		{action: PRActionClose, from: domain.PRStatusOpen, to: domain.PRStatusClosed, changed: true},
+		{action: PRActionClose, from: domain.PRStatusClosed, to: domain.PRStatusClosed},
+		{action: PRActionClose, from: domain.PRStatusMerged, invalid: true},
+
+		{action: PRActionReopen, from: domain.PRStatusClosed, to: domain.PRStatusOpen, changed: true},
+		{action: PRActionReopen, from: domain.PRStatusOpen, to: domain.PRStatusOpen},
+		{action: PRActionReopen, from: domain.PRStatusDraft, invalid: true},
+		{action: PRActionReopen, from: domain.PRStatusMerged, invalid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.action)+"/"+string(tt.from), func(t *testing.T) {
+			to, changed, err := nextStatus(tt.action, tt.from)
+			if tt.invalid {
+				var transitionErr *TransitionError
+				if !errors.As(err, &transitionErr) || !errors.Is(err, domain.ErrInvalidTransition) {
+					t.Fatalf("expected TransitionError, got %v", err)
+				}
+				if transitionErr.Action != tt.action || transitionErr.From != tt.from {
+					t.Errorf("unexpected error fields: %+v", transitionErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if to != tt.to || changed != tt.changed {
+				t.Errorf("got (%s, %v), want (%s, %v)", to, changed, tt.to, tt.changed)
+			}
+		})
+	}
+}
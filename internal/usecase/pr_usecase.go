@@ -1,92 +1,138 @@
 package usecase
 
 import (
+	"context"
 	"errors"
-	"math/rand"
+	"fmt"
 	"github.com/danonenka/PR-service/internal/domain"
 	"time"
 )
 
 type PRUsecase struct {
-	prRepo              domain.PullRequestRepository
-	userRepo            domain.UserRepository
-	assignmentRepo      domain.ReviewerAssignmentRepository
-	reviewerService     *ReviewerService
+	prRepo          domain.PullRequestRepository
+	userRepo        domain.UserRepository
+	teamRepo        domain.TeamRepository
+	assignmentRepo  domain.ReviewerAssignmentRepository
+	eventRepo       domain.PREventRepository
+	txManager       domain.TxManager
+	reviewerService *ReviewerService
+	metrics         Metrics
 }
 
 func NewPRUsecase(
 	prRepo domain.PullRequestRepository,
 	userRepo domain.UserRepository,
+	teamRepo domain.TeamRepository,
 	assignmentRepo domain.ReviewerAssignmentRepository,
+	eventRepo domain.PREventRepository,
+	txManager domain.TxManager,
+	reviewerService *ReviewerService,
 ) *PRUsecase {
 	return &PRUsecase{
 		prRepo:          prRepo,
 		userRepo:        userRepo,
+		teamRepo:        teamRepo,
 		assignmentRepo:  assignmentRepo,
-		reviewerService: NewReviewerService(userRepo, assignmentRepo),
+		eventRepo:       eventRepo,
+		txManager:       txManager,
+		reviewerService: reviewerService,
+		metrics:         noopMetrics{},
 	}
 }
 
-func (u *PRUsecase) CreatePR(pr *domain.PullRequest) error {
-	author, err := u.userRepo.GetByID(pr.AuthorID)
-	if err != nil {
-		return errors.New("author not found")
-	}
-
-	teamUsers, err := u.userRepo.GetActiveByTeamID(author.TeamID)
-	if err != nil {
-		return err
-	}
+// SetMetrics подключает учёт доменных событий: созданных и смерженных PR,
+// назначений ревьюеров.
+func (u *PRUsecase) SetMetrics(metrics Metrics) {
+	u.metrics = metrics
+}
 
-	candidates := make([]*domain.User, 0)
-	for _, user := range teamUsers {
-		if user.ID != pr.AuthorID {
-			candidates = append(candidates, user)
+// CreatePR создаёт PR и назначает ревьюеров. actorID — инициатор для
+// журнала изменений, может быть пустым.
+func (u *PRUsecase) CreatePR(ctx context.Context, pr *domain.PullRequest, actorID string) error {
+	err := u.txManager.WithinTx(ctx, func(uow domain.UnitOfWork) error {
+		author, err := uow.Users().GetByID(ctx, pr.AuthorID)
+		if err != nil {
+			return notFound("author", err)
 		}
-	}
 
-	reviewerCount := 2
-	if len(candidates) < reviewerCount {
-		reviewerCount = len(candidates)
-	}
-
-	rand.Seed(time.Now().UnixNano())
-	selectedReviewers := make([]*domain.User, 0, reviewerCount)
-	availableCandidates := make([]*domain.User, len(candidates))
-	copy(availableCandidates, candidates)
+		team, err := uow.Teams().GetByID(ctx, author.TeamID)
+		if err != nil {
+			return err
+		}
 
-	for i := 0; i < reviewerCount && len(availableCandidates) > 0; i++ {
-		idx := rand.Intn(len(availableCandidates))
-		selectedReviewers = append(selectedReviewers, availableCandidates[idx])
-		availableCandidates = append(availableCandidates[:idx], availableCandidates[idx+1:]...)
-	}
+		// Черновику ревьюеры назначаются только при markReady
+		var selectedReviewers []*domain.User
+		if pr.Status != domain.PRStatusDraft {
+			selectedReviewers, err = u.reviewerService.PickReviewers(ctx, uow, team, map[string]bool{pr.AuthorID: true}, team.MaxReviewers)
+			if err != nil {
+				return err
+			}
+		}
 
-	if err := u.prRepo.Create(pr); err != nil {
-		return err
-	}
+		now := time.Now().UTC()
+		if pr.CreatedAt.IsZero() {
+			pr.CreatedAt = now
+		}
+		pr.UpdatedAt = now
 
-	pr.ReviewerIDs = make([]string, 0, len(selectedReviewers))
-	for _, reviewer := range selectedReviewers {
-		assignment := &domain.ReviewerAssignment{
-			PRID:       pr.ID,
-			ReviewerID: reviewer.ID,
+		if err := uow.PullRequests().Create(ctx, pr); err != nil {
+			if errors.Is(err, domain.ErrConflict) {
+				return domain.ErrPRExists
+			}
+			return err
 		}
-		if err := u.assignmentRepo.Create(assignment); err != nil {
+		if err := recordEvents(ctx, uow, &domain.PREvent{
+			PRID:     pr.ID,
+			Type:     domain.PREventCreated,
+			ActorID:  actorID,
+			ToStatus: pr.Status,
+		}); err != nil {
 			return err
 		}
-		pr.ReviewerIDs = append(pr.ReviewerIDs, reviewer.ID)
+
+		pr.ReviewerIDs = make([]string, 0, len(selectedReviewers))
+		for _, reviewer := range selectedReviewers {
+			assignment := &domain.ReviewerAssignment{
+				PRID:       pr.ID,
+				ReviewerID: reviewer.ID,
+			}
+			if err := uow.Assignments().Create(ctx, assignment); err != nil {
+				return err
+			}
+			if err := recordEvents(ctx, uow, reviewerAssignedEvent(pr.ID, reviewer.ID, domain.AssignmentReasonAuto, actorID)); err != nil {
+				return err
+			}
+			pr.ReviewerIDs = append(pr.ReviewerIDs, reviewer.ID)
+		}
+		pr.RequiredReviewers = team.MinReviewers
+
+		return reviewerAssignedWebhooks(ctx, uow, team.ID, pr, pr.ReviewerIDs, domain.AssignmentReasonAuto, actorID)
+	})
+	if err != nil {
+		return err
 	}
 
+	u.metrics.PRCreated()
+	u.recordStaffing(pr, len(pr.ReviewerIDs))
 	return nil
 }
 
-func (u *PRUsecase) GetPRByID(id string) (*domain.PullRequest, error) {
-	pr, err := u.prRepo.GetByID(id)
+// recordStaffing учитывает автоматически назначенных ревьюеров и PR,
+// которым не хватило ревьюеров до min_reviewers.
+func (u *PRUsecase) recordStaffing(pr *domain.PullRequest, added int) {
+	u.metrics.ReviewersAssigned(domain.AssignmentReasonAuto, added)
+	if pr.IsUnderstaffed() {
+		u.metrics.PRUnderstaffed(domain.AssignmentReasonAuto)
+	}
+}
+
+func (u *PRUsecase) GetPRByID(ctx context.Context, id string) (*domain.PullRequest, error) {
+	pr, err := u.prRepo.GetByID(ctx, id)
 	if err != nil {
-		return nil, err
+		return nil, notFound("PR", err)
 	}
 
-	assignments, err := u.assignmentRepo.GetByPRID(id)
+	assignments, err := u.assignmentRepo.GetByPRID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -95,139 +141,530 @@ func (u *PRUsecase) GetPRByID(id string) (*domain.PullRequest, error) {
 	for _, assignment := range assignments {
 		pr.ReviewerIDs = append(pr.ReviewerIDs, assignment.ReviewerID)
 	}
+	pr.Reviews = assignments
+
+	author, err := u.userRepo.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+	team, err := u.teamRepo.GetByID(ctx, author.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	pr.RequiredReviewers = team.MinReviewers
 
 	return pr, nil
 }
 
-func (u *PRUsecase) GetPRsByAuthorID(authorID string) ([]*domain.PullRequest, error) {
-	prs, err := u.prRepo.GetByAuthorID(authorID)
+func (u *PRUsecase) GetPRsByAuthorID(ctx context.Context, authorID string) ([]*domain.PullRequest, error) {
+	return u.prRepo.GetByAuthorID(ctx, authorID)
+}
+
+func (u *PRUsecase) GetPRsByReviewerID(ctx context.Context, reviewerID string) ([]*domain.PullRequest, error) {
+	return u.prRepo.GetByReviewerID(ctx, reviewerID)
+}
+
+// GetReviewPage возвращает страницу PR, назначенных ревьюеру, в порядке
+// создания. cursor — NextCursor предыдущей страницы или пустая строка.
+func (u *PRUsecase) GetReviewPage(ctx context.Context, reviewerID string, status domain.PRStatus, limit int, cursor string) (*PRPage, error) {
+	return listPage(ctx, u.prRepo, domain.PRListFilter{
+		ReviewerID: reviewerID,
+		Status:     status,
+		Limit:      limit,
+	}, cursor)
+}
+
+// PRListQuery — параметры /pullRequest/list. TeamName — команда автора.
+type PRListQuery struct {
+	AuthorID    string
+	TeamName    string
+	ReviewerID  string
+	Status      domain.PRStatus
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	SortBy      domain.PRSortField
+	Descending  bool
+	Limit       int
+	Cursor      string
+}
+
+// ListPRs возвращает страницу PR по фильтру. У каждого PR заполнены
+// ReviewerIDs и RequiredReviewers.
+func (u *PRUsecase) ListPRs(ctx context.Context, query PRListQuery) (*PRPage, error) {
+	filter := domain.PRListFilter{
+		AuthorID:    query.AuthorID,
+		ReviewerID:  query.ReviewerID,
+		Status:      query.Status,
+		CreatedFrom: query.CreatedFrom,
+		CreatedTo:   query.CreatedTo,
+		SortBy:      query.SortBy,
+		Descending:  query.Descending,
+		Limit:       query.Limit,
+	}
+	if query.TeamName != "" {
+		team, err := u.teamRepo.GetByName(ctx, query.TeamName)
+		if err != nil {
+			return nil, notFound("team", err)
+		}
+		filter.TeamID = team.ID
+	}
+
+	page, err := listPage(ctx, u.prRepo, filter, query.Cursor)
 	if err != nil {
 		return nil, err
 	}
+	if err := u.fillRequiredReviewers(ctx, page.Items); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// fillRequiredReviewers проставляет min_reviewers команды автора, читая
+// авторов одним запросом и каждую команду один раз.
+func (u *PRUsecase) fillRequiredReviewers(ctx context.Context, prs []*domain.PullRequest) error {
+	if len(prs) == 0 {
+		return nil
+	}
+
+	authorIDs := make([]string, 0, len(prs))
+	seen := make(map[string]bool, len(prs))
+	for _, pr := range prs {
+		if !seen[pr.AuthorID] {
+			seen[pr.AuthorID] = true
+			authorIDs = append(authorIDs, pr.AuthorID)
+		}
+	}
+
+	authors, err := u.userRepo.GetByIDs(ctx, authorIDs)
+	if err != nil {
+		return err
+	}
+	minByTeam := make(map[string]int)
+	minByAuthor := make(map[string]int, len(authors))
+	for _, author := range authors {
+		minReviewers, ok := minByTeam[author.TeamID]
+		if !ok {
+			team, err := u.teamRepo.GetByID(ctx, author.TeamID)
+			if err != nil {
+				return err
+			}
+			minReviewers = team.MinReviewers
+			minByTeam[author.TeamID] = minReviewers
+		}
+		minByAuthor[author.ID] = minReviewers
+	}
 
 	for _, pr := range prs {
-		assignments, err := u.assignmentRepo.GetByPRID(pr.ID)
+		pr.RequiredReviewers = minByAuthor[pr.AuthorID]
+	}
+	return nil
+}
+
+// ReassignReviewer заменяет ревьюера на другого участника его команды и
+// возвращает user_id нового ревьюера. Строка PR блокируется на время
+// транзакции, поэтому параллельные переназначения и merge выполняются
+// по очереди.
+func (u *PRUsecase) ReassignReviewer(ctx context.Context, prID string, oldReviewerID string, actorID string) (string, error) {
+	var newReviewerID string
+	err := u.txManager.WithinTx(ctx, func(uow domain.UnitOfWork) error {
+		pr, err := uow.PullRequests().GetByIDForUpdate(ctx, prID)
 		if err != nil {
-			return nil, err
+			return notFound("PR", err)
+		}
+
+		if pr.Status == domain.PRStatusMerged {
+			return fmt.Errorf("cannot reassign reviewers: %w", domain.ErrPRMerged)
+		}
+		if pr.Status != domain.PRStatusOpen {
+			return domain.ErrPRNotOpen
+		}
+
+		oldReviewer, err := uow.Users().GetByID(ctx, oldReviewerID)
+		if err != nil {
+			return notFound("old reviewer", err)
+		}
+
+		excludedIDs := make(map[string]bool)
+		excludedIDs[pr.AuthorID] = true
+		excludedIDs[oldReviewerID] = true
+
+		assignments, err := uow.Assignments().GetByPRID(ctx, prID)
+		if err != nil {
+			return err
 		}
+		assigned := false
 		pr.ReviewerIDs = make([]string, 0, len(assignments))
 		for _, assignment := range assignments {
+			excludedIDs[assignment.ReviewerID] = true
+			if assignment.ReviewerID == oldReviewerID {
+				assigned = true
+				continue
+			}
 			pr.ReviewerIDs = append(pr.ReviewerIDs, assignment.ReviewerID)
 		}
+		if !assigned {
+			return domain.ErrNotAssigned
+		}
+
+		team, err := uow.Teams().GetByID(ctx, oldReviewer.TeamID)
+		if err != nil {
+			return err
+		}
+
+		candidates, err := u.reviewerService.PickReviewers(ctx, uow, team, excludedIDs, 1)
+		if err != nil {
+			return err
+		}
+		if len(candidates) == 0 {
+			return domain.ErrNoCandidate
+		}
+		newReviewer := candidates[0]
+
+		if err := uow.Assignments().Delete(ctx, prID, oldReviewerID); err != nil {
+			return err
+		}
+
+		newAssignment := &domain.ReviewerAssignment{
+			PRID:       prID,
+			ReviewerID: newReviewer.ID,
+		}
+		if err := uow.Assignments().Create(ctx, newAssignment); err != nil {
+			return err
+		}
+		if err := recordEvents(ctx, uow,
+			reviewerRemovedEvent(prID, oldReviewerID, domain.AssignmentReasonManualReassign, actorID),
+			reviewerAssignedEvent(prID, newReviewer.ID, domain.AssignmentReasonManualReassign, actorID),
+		); err != nil {
+			return err
+		}
+
+		pr.UpdatedAt = time.Now().UTC()
+		if err := uow.PullRequests().Update(ctx, pr); err != nil {
+			return err
+		}
+
+		pr.ReviewerIDs = append(pr.ReviewerIDs, newReviewer.ID)
+		if err := reviewerReassignedWebhook(ctx, uow, team.ID, pr, oldReviewerID, newReviewer.ID, domain.AssignmentReasonManualReassign, actorID); err != nil {
+			return err
+		}
+
+		newReviewerID = newReviewer.ID
+		return nil
+	})
+	if err != nil {
+		return "", err
 	}
 
-	return prs, nil
+	u.metrics.ReviewersAssigned(domain.AssignmentReasonManualReassign, 1)
+	u.metrics.Reassignment(domain.AssignmentReasonManualReassign, OutcomeReassigned)
+	return newReviewerID, nil
+}
+
+// GetPRHistory возвращает журнал изменений PR от старых событий к новым.
+func (u *PRUsecase) GetPRHistory(ctx context.Context, prID string) ([]*domain.PREvent, error) {
+	if _, err := u.prRepo.GetByID(ctx, prID); err != nil {
+		return nil, notFound("PR", err)
+	}
+	return u.eventRepo.GetByPRID(ctx, prID)
 }
 
-func (u *PRUsecase) GetPRsByReviewerID(reviewerID string) ([]*domain.PullRequest, error) {
-	assignments, err := u.assignmentRepo.GetByReviewerID(reviewerID)
+// SubmitReview сохраняет вердикт ревьюера по PR. Повторный вызов
+// перезаписывает предыдущий вердикт.
+func (u *PRUsecase) SubmitReview(ctx context.Context, prID string, reviewerID string, state domain.ReviewState, actorID string) (*domain.ReviewerAssignment, error) {
+	var review *domain.ReviewerAssignment
+	err := u.txManager.WithinTx(ctx, func(uow domain.UnitOfWork) error {
+		pr, err := uow.PullRequests().GetByIDForUpdate(ctx, prID)
+		if err != nil {
+			return notFound("PR", err)
+		}
+
+		if pr.Status == domain.PRStatusMerged {
+			return fmt.Errorf("cannot review: %w", domain.ErrPRMerged)
+		}
+		if pr.Status != domain.PRStatusOpen {
+			return domain.ErrPRNotOpen
+		}
+
+		assignments, err := uow.Assignments().GetByPRID(ctx, prID)
+		if err != nil {
+			return err
+		}
+		for _, assignment := range assignments {
+			if assignment.ReviewerID == reviewerID {
+				review = assignment
+				break
+			}
+		}
+		if review == nil {
+			return domain.ErrNotAssigned
+		}
+
+		now := time.Now().UTC()
+		review.State = state
+		review.ReviewedAt = &now
+		if err := uow.Assignments().UpdateState(ctx, review); err != nil {
+			return err
+		}
+
+		pr.UpdatedAt = now
+		if err := uow.PullRequests().Update(ctx, pr); err != nil {
+			return err
+		}
+		return recordEvents(ctx, uow, reviewSubmittedEvent(prID, reviewerID, state, actorID))
+	})
 	if err != nil {
 		return nil, err
 	}
+	return review, nil
+}
 
-	prs := make([]*domain.PullRequest, 0, len(assignments))
-	for _, assignment := range assignments {
-		pr, err := u.prRepo.GetByID(assignment.PRID)
+// MergePR мержит открытый PR. Если команда автора требует одобрений, а
+// кворум не набран, возвращает domain.ErrNotApproved.
+func (u *PRUsecase) MergePR(ctx context.Context, prID string, actorID string) error {
+	merged := false
+	err := u.txManager.WithinTx(ctx, func(uow domain.UnitOfWork) error {
+		pr, err := uow.PullRequests().GetByIDForUpdate(ctx, prID)
 		if err != nil {
-			continue
+			return notFound("PR", err)
+		}
+
+		// Если уже merged, просто возвращаем успех (идемпотентность)
+		_, changed, err := nextStatus(PRActionMerge, pr.Status)
+		if err != nil || !changed {
+			return err
 		}
 
-		allAssignments, err := u.assignmentRepo.GetByPRID(pr.ID)
+		author, err := uow.Users().GetByID(ctx, pr.AuthorID)
 		if err != nil {
-			continue
+			return err
+		}
+		team, err := uow.Teams().GetByID(ctx, author.TeamID)
+		if err != nil {
+			return err
+		}
+		assignments, err := uow.Assignments().GetByPRID(ctx, prID)
+		if err != nil {
+			return err
 		}
-		pr.ReviewerIDs = make([]string, 0, len(allAssignments))
-		for _, a := range allAssignments {
-			pr.ReviewerIDs = append(pr.ReviewerIDs, a.ReviewerID)
+		if !domain.ApprovalQuorumMet(assignments, team.RequiredApprovals) {
+			return domain.ErrNotApproved
 		}
 
-		prs = append(prs, pr)
+		if err := recordEvents(ctx, uow, statusChangedEvent(pr.ID, pr.Status, domain.PRStatusMerged, actorID)); err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		pr.Status = domain.PRStatusMerged
+		pr.MergedAt = &now
+		pr.UpdatedAt = now
+		if err := uow.PullRequests().Update(ctx, pr); err != nil {
+			return err
+		}
+
+		pr.ReviewerIDs = make([]string, 0, len(assignments))
+		for _, assignment := range assignments {
+			pr.ReviewerIDs = append(pr.ReviewerIDs, assignment.ReviewerID)
+		}
+		merged = true
+		return enqueueWebhook(ctx, uow, newWebhookPayload(domain.WebhookEventPRMerged, team.ID, pr, actorID))
+	})
+	if err == nil && merged {
+		u.metrics.PRMerged()
 	}
+	return err
+}
 
-	return prs, nil
+// ClosePR закрывает черновик или открытый PR без merge. Ревьюеры остаются
+// на PR, но закрытый PR не учитывается в их нагрузке.
+func (u *PRUsecase) ClosePR(ctx context.Context, prID string, actorID string) error {
+	return u.transition(ctx, prID, actorID, PRActionClose, func(uow domain.UnitOfWork, pr *domain.PullRequest, now time.Time) error {
+		pr.ClosedAt = &now
+		return nil
+	})
 }
 
-func (u *PRUsecase) ReassignReviewer(prID string, oldReviewerID string) error {
-	pr, err := u.prRepo.GetByID(prID)
-	if err != nil {
-		return errors.New("PR not found")
-	}
+// ReopenPR возвращает закрытый PR в OPEN. Ревьюеры, ставшие неактивными,
+// снимаются, и состав добирается до max_reviewers команды.
+func (u *PRUsecase) ReopenPR(ctx context.Context, prID string, actorID string) error {
+	return u.transitionWithStaffing(ctx, prID, actorID, PRActionReopen, func(pr *domain.PullRequest) {
+		pr.ClosedAt = nil
+	})
+}
 
-	if pr.Status == domain.PRStatusMerged {
-		return errors.New("cannot reassign reviewers for merged PR")
+// MarkReady переводит черновик в OPEN и назначает ревьюеров.
+func (u *PRUsecase) MarkReady(ctx context.Context, prID string, actorID string) error {
+	return u.transitionWithStaffing(ctx, prID, actorID, PRActionMarkReady, func(*domain.PullRequest) {})
+}
+
+// transitionWithStaffing — переход в OPEN с добором ревьюеров через
+// staffReviewers; prepare меняет PR до добора.
+func (u *PRUsecase) transitionWithStaffing(ctx context.Context, prID string, actorID string, action PRAction, prepare func(pr *domain.PullRequest)) error {
+	var staffed *domain.PullRequest
+	added := 0
+	err := u.transition(ctx, prID, actorID, action, func(uow domain.UnitOfWork, pr *domain.PullRequest, _ time.Time) error {
+		prepare(pr)
+		var err error
+		added, err = u.staffReviewers(ctx, uow, pr, actorID)
+		staffed = pr
+		return err
+	})
+	if err == nil && staffed != nil {
+		u.recordStaffing(staffed, added)
 	}
+	return err
+}
+
+// transition блокирует PR, проверяет переход по prTransitions и сохраняет
+// новый статус. apply вызывается до сохранения, уже с новым статусом.
+// Повторный вызов для PR в целевом статусе ничего не меняет.
+func (u *PRUsecase) transition(ctx context.Context, prID string, actorID string, action PRAction, apply func(uow domain.UnitOfWork, pr *domain.PullRequest, now time.Time) error) error {
+	return u.txManager.WithinTx(ctx, func(uow domain.UnitOfWork) error {
+		pr, err := uow.PullRequests().GetByIDForUpdate(ctx, prID)
+		if err != nil {
+			return notFound("PR", err)
+		}
+
+		next, changed, err := nextStatus(action, pr.Status)
+		if err != nil || !changed {
+			return err
+		}
+
+		if err := recordEvents(ctx, uow, statusChangedEvent(pr.ID, pr.Status, next, actorID)); err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		pr.Status = next
+		pr.UpdatedAt = now
+		if err := apply(uow, pr, now); err != nil {
+			return err
+		}
+		return uow.PullRequests().Update(ctx, pr)
+	})
+}
 
-	oldReviewer, err := u.userRepo.GetByID(oldReviewerID)
+// staffReviewers снимает с PR неактивных ревьюеров и добирает активных
+// участников команды автора до max_reviewers. Возвращает, сколько
+// ревьюеров добавлено.
+func (u *PRUsecase) staffReviewers(ctx context.Context, uow domain.UnitOfWork, pr *domain.PullRequest, actorID string) (int, error) {
+	author, err := uow.Users().GetByID(ctx, pr.AuthorID)
 	if err != nil {
-		return errors.New("old reviewer not found")
+		return 0, err
 	}
-
-	teamUsers, err := u.userRepo.GetActiveByTeamID(oldReviewer.TeamID)
+	team, err := uow.Teams().GetByID(ctx, author.TeamID)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	candidates := make([]*domain.User, 0)
-	excludedIDs := make(map[string]bool)
-	excludedIDs[pr.AuthorID] = true
-	excludedIDs[oldReviewerID] = true
-
-	assignments, err := u.assignmentRepo.GetByPRID(prID)
+	assignments, err := uow.Assignments().GetByPRID(ctx, pr.ID)
 	if err != nil {
-		return err
+		return 0, err
 	}
+	assignedIDs := make([]string, 0, len(assignments))
 	for _, assignment := range assignments {
-		excludedIDs[assignment.ReviewerID] = true
+		assignedIDs = append(assignedIDs, assignment.ReviewerID)
+	}
+	reviewers, err := uow.Users().GetByIDs(ctx, assignedIDs)
+	if err != nil {
+		return 0, err
+	}
+	active := make(map[string]bool, len(reviewers))
+	for _, reviewer := range reviewers {
+		active[reviewer.ID] = reviewer.IsActive
 	}
 
-	for _, user := range teamUsers {
-		if !excludedIDs[user.ID] {
-			candidates = append(candidates, user)
+	excludedIDs := map[string]bool{pr.AuthorID: true}
+	pr.ReviewerIDs = make([]string, 0, team.MaxReviewers)
+	for _, assignment := range assignments {
+		excludedIDs[assignment.ReviewerID] = true
+		if !active[assignment.ReviewerID] {
+			if err := uow.Assignments().Delete(ctx, pr.ID, assignment.ReviewerID); err != nil {
+				return 0, err
+			}
+			if err := recordEvents(ctx, uow, reviewerRemovedEvent(pr.ID, assignment.ReviewerID, domain.AssignmentReasonDeactivation, actorID)); err != nil {
+				return 0, err
+			}
+			continue
 		}
+		pr.ReviewerIDs = append(pr.ReviewerIDs, assignment.ReviewerID)
 	}
 
-	if len(candidates) == 0 {
-		return errors.New("no available reviewers")
+	candidates, err := u.reviewerService.PickReviewers(ctx, uow, team, excludedIDs, team.MaxReviewers-len(pr.ReviewerIDs))
+	if err != nil {
+		return 0, err
 	}
+	addedIDs := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if err := uow.Assignments().Create(ctx, &domain.ReviewerAssignment{PRID: pr.ID, ReviewerID: candidate.ID}); err != nil {
+			return 0, err
+		}
+		if err := recordEvents(ctx, uow, reviewerAssignedEvent(pr.ID, candidate.ID, domain.AssignmentReasonAuto, actorID)); err != nil {
+			return 0, err
+		}
+		pr.ReviewerIDs = append(pr.ReviewerIDs, candidate.ID)
+		addedIDs = append(addedIDs, candidate.ID)
+	}
+	pr.RequiredReviewers = team.MinReviewers
 
-	rand.Seed(time.Now().UnixNano())
-	newReviewer := candidates[rand.Intn(len(candidates))]
-
-	if err := u.assignmentRepo.Delete(prID, oldReviewerID); err != nil {
-		return err
+	if err := reviewerAssignedWebhooks(ctx, uow, team.ID, pr, addedIDs, domain.AssignmentReasonAuto, actorID); err != nil {
+		return 0, err
 	}
+	return len(addedIDs), nil
+}
+
+type ReviewerService struct {
+	strategies map[domain.ReviewerStrategy]ReviewerSelectionStrategy
+}
 
-	newAssignment := &domain.ReviewerAssignment{
-		PRID:       prID,
-		ReviewerID: newReviewer.ID,
+func NewReviewerService() *ReviewerService {
+	return &ReviewerService{
+		strategies: NewReviewerSelectionStrategies(),
 	}
-	return u.assignmentRepo.Create(newAssignment)
 }
 
-func (u *PRUsecase) MergePR(prID string) error {
-	pr, err := u.prRepo.GetByID(prID)
+// PickReviewers выбирает до count активных участников команды, не входящих
+// в excludedIDs, по стратегии, настроенной для команды. Чтение идёт через
+// uow, чтобы выбор видел изменения текущей транзакции.
+func (s *ReviewerService) PickReviewers(ctx context.Context, uow domain.UnitOfWork, team *domain.Team, excludedIDs map[string]bool, count int) ([]*domain.User, error) {
+	teamUsers, err := uow.Users().GetActiveByTeamID(ctx, team.ID)
 	if err != nil {
-		return errors.New("PR not found")
+		return nil, err
 	}
 
-	// Если уже merged, просто возвращаем успех (идемпотентность)
-	if pr.Status == domain.PRStatusMerged {
-		return nil
+	users := make([]*domain.User, 0, len(teamUsers))
+	ids := make([]string, 0, len(teamUsers))
+	for _, user := range teamUsers {
+		if !excludedIDs[user.ID] {
+			users = append(users, user)
+			ids = append(ids, user.ID)
+		}
 	}
 
-	pr.Status = domain.PRStatusMerged
-	return u.prRepo.Update(pr)
-}
+	if len(users) == 0 || count <= 0 {
+		return []*domain.User{}, nil
+	}
 
-type ReviewerService struct {
-	userRepo       domain.UserRepository
-	assignmentRepo domain.ReviewerAssignmentRepository
+	openReviews, err := uow.Assignments().CountOpenByReviewerIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]ReviewerCandidate, 0, len(users))
+	for _, user := range users {
+		candidates = append(candidates, ReviewerCandidate{
+			User:        user,
+			OpenReviews: openReviews[user.ID],
+		})
+	}
+
+	return s.strategyFor(team).SelectReviewers(team.ID, candidates, count), nil
 }
 
-func NewReviewerService(userRepo domain.UserRepository, assignmentRepo domain.ReviewerAssignmentRepository) *ReviewerService {
-	return &ReviewerService{
-		userRepo:       userRepo,
-		assignmentRepo: assignmentRepo,
+func (s *ReviewerService) strategyFor(team *domain.Team) ReviewerSelectionStrategy {
+	if strategy, ok := s.strategies[team.ReviewerStrategy]; ok {
+		return strategy
 	}
+	return s.strategies[domain.ReviewerStrategyRandom]
 }
 
@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danonenka/PR-service/internal/domain"
+	"github.com/danonenka/PR-service/internal/repository/memory"
+)
+
+func TestSubmitReviewRecordsEvent(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	userRepo := memory.NewUserRepository(store)
+	teamRepo := memory.NewTeamRepository(store)
+	txManager := memory.NewTxManager(store)
+	prUsecase := NewPRUsecase(memory.NewPullRequestRepository(store), userRepo, teamRepo,
+		memory.NewReviewerAssignmentRepository(store), memory.NewPREventRepository(store), txManager, NewReviewerService())
+
+	members := []*domain.User{{ID: "u1", Name: "u1", IsActive: true}, {ID: "u2", Name: "u2", IsActive: true}}
+	if _, err := NewTeamUsecase(teamRepo, userRepo, txManager).AddTeamWithMembers(ctx, "backend", "", members); err != nil {
+		t.Fatalf("add team: %v", err)
+	}
+	pr := &domain.PullRequest{ID: "pr-1", Title: "t", AuthorID: "u1", Status: domain.PRStatusOpen}
+	if err := prUsecase.CreatePR(ctx, pr, "u1"); err != nil {
+		t.Fatalf("create PR: %v", err)
+	}
+
+	if _, err := prUsecase.SubmitReview(ctx, "pr-1", "u2", domain.ReviewStateApproved, "u2"); err != nil {
+		t.Fatalf("submit review: %v", err)
+	}
+
+	events, err := prUsecase.GetPRHistory(ctx, "pr-1")
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	last := events[len(events)-1]
+	if last.Type != domain.PREventReviewSubmitted || last.ReviewerID != "u2" || last.ActorID != "u2" ||
+		last.ReviewState != domain.ReviewStateApproved {
+		t.Errorf("unexpected last event: %+v", last)
+	}
+}
@@ -1,116 +1,350 @@
 package usecase
 
 import (
-	"math/rand"
-	"github.com/danonenka/PR-service/internal/domain"
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"sync"
 	"time"
+
+	"github.com/danonenka/PR-service/internal/domain"
 )
 
+const (
+	defaultReassignWorkers  = 8
+	defaultReassignDeadline = 10 * time.Second
+)
+
+// Тексты ошибок в отчёте переназначения: он уходит клиенту, поэтому
+// подробности драйвера и SQL остаются только в логе.
+const (
+	reportErrorDeadline = "deadline exceeded"
+	reportErrorInternal = "internal error"
+)
+
+// reportedErrors — доменные ошибки, описание которых можно показать клиенту.
+var reportedErrors = []error{
+	domain.ErrConcurrentModification,
+	domain.ErrPRMerged,
+	domain.ErrPRNotOpen,
+	domain.ErrNoCandidate,
+	domain.ErrNotAssigned,
+	domain.ErrInvalidTransition,
+	domain.ErrNotFound,
+	domain.ErrConflict,
+}
+
 type ReassignmentUsecase struct {
-	prRepo         domain.PullRequestRepository
-	userRepo       domain.UserRepository
-	assignmentRepo domain.ReviewerAssignmentRepository
+	prRepo          domain.PullRequestRepository
+	txManager       domain.TxManager
+	reviewerService *ReviewerService
+	workers         int
+	deadline        time.Duration
+	metrics         Metrics
 }
 
 func NewReassignmentUsecase(
 	prRepo domain.PullRequestRepository,
-	userRepo domain.UserRepository,
-	assignmentRepo domain.ReviewerAssignmentRepository,
+	txManager domain.TxManager,
+	reviewerService *ReviewerService,
 ) *ReassignmentUsecase {
 	return &ReassignmentUsecase{
-		prRepo:         prRepo,
-		userRepo:       userRepo,
-		assignmentRepo: assignmentRepo,
+		prRepo:          prRepo,
+		txManager:       txManager,
+		reviewerService: reviewerService,
+		workers:         defaultReassignWorkers,
+		deadline:        defaultReassignDeadline,
+		metrics:         noopMetrics{},
+	}
+}
+
+// SetMetrics подключает учёт переназначений при деактивации.
+func (u *ReassignmentUsecase) SetMetrics(metrics Metrics) {
+	u.metrics = metrics
+}
+
+// SetBatchLimits задаёт, сколько PR переназначается параллельно и сколько
+// времени отводится на весь пакет. Неположительные значения игнорируются.
+func (u *ReassignmentUsecase) SetBatchLimits(workers int, deadline time.Duration) {
+	if workers > 0 {
+		u.workers = workers
 	}
+	if deadline > 0 {
+		u.deadline = deadline
+	}
+}
+
+type ReassignmentOutcome string
+
+const (
+	// OutcomeReassigned — выбывшего ревьюера заменили другим участником
+	OutcomeReassigned ReassignmentOutcome = "REASSIGNED"
+	// OutcomeRemoved — ревьюера сняли, замена не нужна: у PR и так max_reviewers
+	OutcomeRemoved ReassignmentOutcome = "REMOVED"
+	// OutcomeLeftShort — ревьюера сняли, а подходящей замены в команде нет
+	OutcomeLeftShort ReassignmentOutcome = "LEFT_SHORT"
+	// OutcomeFailed — переназначение не удалось, ревьюер остался на PR
+	OutcomeFailed ReassignmentOutcome = "FAILED"
+)
+
+type PRReassignment struct {
+	PRID          string
+	OldReviewerID string
+	NewReviewerID string
+	Outcome       ReassignmentOutcome
+	// Understaffed — после переназначения у PR меньше min_reviewers
+	Understaffed bool
+	Error        string
+}
+
+type ReassignmentReport struct {
+	Items []PRReassignment
 }
 
-func (u *ReassignmentUsecase) ReassignDeactivatedReviewers(teamID string, deactivatedUserIDs []string) error {
+func (r *ReassignmentReport) add(item PRReassignment) {
+	r.Items = append(r.Items, item)
+}
+
+// ReassignDeactivatedReviewers снимает деактивированных пользователей со всех
+// открытых PR и подбирает им замену. PR обрабатываются параллельно, но не
+// дольше deadline, включая ожидание базы: ревьюеры, до которых очередь не
+// дошла, остаются на PR и попадают в отчёт как FAILED. Ошибка возвращается,
+// только если не удалось получить список PR. actorID — кто деактивировал,
+// для журнала изменений.
+func (u *ReassignmentUsecase) ReassignDeactivatedReviewers(ctx context.Context, teamID string, deactivatedUserIDs []string, actorID string) (*ReassignmentReport, error) {
+	report := &ReassignmentReport{Items: make([]PRReassignment, 0)}
 	if len(deactivatedUserIDs) == 0 {
-		return nil
+		return report, nil
 	}
+	// Дедлайн действует и на запросы к базе: зависшая блокировка или запрос
+	// не растянут пакет дольше отведённого времени
+	ctx, cancel := context.WithTimeout(ctx, u.deadline)
+	defer cancel()
 
-	allPRs, err := u.prRepo.GetAll()
+	prs, err := u.prRepo.GetOpenByReviewerIDs(ctx, deactivatedUserIDs)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	deactivated := make(map[string]bool, len(deactivatedUserIDs))
+	for _, id := range deactivatedUserIDs {
+		deactivated[id] = true
 	}
 
-	openPRs := make([]*domain.PullRequest, 0)
-	for _, pr := range allPRs {
-		if pr.Status == domain.PRStatusOpen {
-			openPRs = append(openPRs, pr)
+	jobs := make(chan *domain.PullRequest)
+	results := make(chan []PRReassignment)
+
+	var wg sync.WaitGroup
+	for i := 0; i < u.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for pr := range jobs {
+				results <- u.reassignPR(ctx, pr, deactivated, actorID)
+			}
+		}()
+	}
+
+	go func() {
+		for _, pr := range prs {
+			jobs <- pr
 		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	for items := range results {
+		report.Items = append(report.Items, items...)
 	}
 
-	for _, pr := range openPRs {
-		assignments, err := u.assignmentRepo.GetByPRID(pr.ID)
-		if err != nil {
+	sort.Slice(report.Items, func(i, j int) bool {
+		if report.Items[i].PRID != report.Items[j].PRID {
+			return report.Items[i].PRID < report.Items[j].PRID
+		}
+		return report.Items[i].OldReviewerID < report.Items[j].OldReviewerID
+	})
+
+	failed := 0
+	for _, item := range report.Items {
+		if item.Outcome == OutcomeFailed {
+			failed++
+		}
+	}
+	slog.InfoContext(ctx, "deactivated reviewers reassigned",
+		"team_id", teamID, "user_ids", deactivatedUserIDs, "items", len(report.Items), "failed", failed)
+
+	return report, nil
+}
+
+// reassignPR снимает с одного PR всех деактивированных ревьюеров по очереди.
+// Список ревьюеров берётся из выборки и перепроверяется под блокировкой
+// в reassignReviewerForPR.
+func (u *ReassignmentUsecase) reassignPR(ctx context.Context, pr *domain.PullRequest, deactivated map[string]bool, actorID string) []PRReassignment {
+	items := make([]PRReassignment, 0)
+
+	for _, reviewerID := range pr.ReviewerIDs {
+		if !deactivated[reviewerID] {
 			continue
 		}
 
-		deactivatedReviewers := make([]string, 0)
-		for _, assignment := range assignments {
-			for _, deactivatedID := range deactivatedUserIDs {
-				if assignment.ReviewerID == deactivatedID {
-					deactivatedReviewers = append(deactivatedReviewers, assignment.ReviewerID)
-					break
-				}
-			}
+		// Дедлайн пакета истёк: не начинаем транзакцию, которая всё равно
+		// будет отменена
+		if ctx.Err() != nil {
+			slog.WarnContext(ctx, "reviewer reassignment skipped: deadline exceeded",
+				"pr_id", pr.ID, "user_id", reviewerID)
+			u.metrics.Reassignment(domain.AssignmentReasonDeactivation, OutcomeFailed)
+			items = append(items, PRReassignment{
+				PRID:          pr.ID,
+				OldReviewerID: reviewerID,
+				Outcome:       OutcomeFailed,
+				Error:         reportErrorDeadline,
+			})
+			continue
 		}
 
-		for _, deactivatedReviewerID := range deactivatedReviewers {
-			if err := u.reassignReviewerForPR(pr, deactivatedReviewerID); err != nil {
-				continue
+		item, err := u.reassignReviewerForPR(ctx, pr.ID, reviewerID, actorID)
+		if err != nil {
+			slog.ErrorContext(ctx, "reviewer reassignment failed",
+				"pr_id", pr.ID, "user_id", reviewerID, "error", err)
+			item = PRReassignment{
+				PRID:          pr.ID,
+				OldReviewerID: reviewerID,
+				Outcome:       OutcomeFailed,
+				Error:         reportError(err),
 			}
 		}
+		if item.Outcome != "" {
+			u.recordReassignment(item)
+			items = append(items, item)
+		}
 	}
 
-	return nil
+	return items
 }
 
-func (u *ReassignmentUsecase) reassignReviewerForPR(pr *domain.PullRequest, oldReviewerID string) error {
-	author, err := u.userRepo.GetByID(pr.AuthorID)
-	if err != nil {
-		return err
+// reportError сводит ошибку переназначения к стабильному тексту для отчёта.
+func reportError(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return reportErrorDeadline
 	}
-
-	teamUsers, err := u.userRepo.GetActiveByTeamID(author.TeamID)
-	if err != nil {
-		return err
+	for _, known := range reportedErrors {
+		if errors.Is(err, known) {
+			return known.Error()
+		}
 	}
+	return reportErrorInternal
+}
 
-	candidates := make([]*domain.User, 0)
-	excludedIDs := make(map[string]bool)
-	excludedIDs[pr.AuthorID] = true
-	excludedIDs[oldReviewerID] = true
-
-	assignments, err := u.assignmentRepo.GetByPRID(pr.ID)
-	if err != nil {
-		return err
+func (u *ReassignmentUsecase) recordReassignment(item PRReassignment) {
+	u.metrics.Reassignment(domain.AssignmentReasonDeactivation, item.Outcome)
+	if item.Outcome == OutcomeReassigned {
+		u.metrics.ReviewersAssigned(domain.AssignmentReasonDeactivation, 1)
 	}
-	for _, assignment := range assignments {
-		excludedIDs[assignment.ReviewerID] = true
+	if item.Understaffed {
+		u.metrics.PRUnderstaffed(domain.AssignmentReasonDeactivation)
 	}
+}
 
-	for _, user := range teamUsers {
-		if !excludedIDs[user.ID] {
-			candidates = append(candidates, user)
+// reassignReviewerForPR заменяет выбывшего ревьюера в отдельной транзакции,
+// чтобы сбой на одном PR не откатывал уже выполненные переназначения.
+// Пустой Outcome означает, что делать ничего не пришлось: PR уже закрыт
+// или ревьюера на нём больше нет.
+func (u *ReassignmentUsecase) reassignReviewerForPR(ctx context.Context, prID string, oldReviewerID string, actorID string) (PRReassignment, error) {
+	item := PRReassignment{PRID: prID, OldReviewerID: oldReviewerID}
+	err := u.txManager.WithinTx(ctx, func(uow domain.UnitOfWork) error {
+		// Перечитываем PR под блокировкой: пока шёл обход, его могли смержить
+		// или уже переназначить ревьюера
+		pr, err := uow.PullRequests().GetByIDForUpdate(ctx, prID)
+		if err != nil {
+			return err
+		}
+		if pr.Status != domain.PRStatusOpen {
+			return nil
 		}
-	}
 
-	if len(candidates) == 0 {
-		return u.assignmentRepo.Delete(pr.ID, oldReviewerID)
-	}
+		author, err := uow.Users().GetByID(ctx, pr.AuthorID)
+		if err != nil {
+			return err
+		}
 
-	rand.Seed(time.Now().UnixNano())
-	newReviewer := candidates[rand.Intn(len(candidates))]
+		excludedIDs := make(map[string]bool)
+		excludedIDs[pr.AuthorID] = true
+		excludedIDs[oldReviewerID] = true
 
-	if err := u.assignmentRepo.Delete(pr.ID, oldReviewerID); err != nil {
-		return err
-	}
+		assignments, err := uow.Assignments().GetByPRID(ctx, pr.ID)
+		if err != nil {
+			return err
+		}
+		assigned := false
+		pr.ReviewerIDs = make([]string, 0, len(assignments))
+		for _, assignment := range assignments {
+			excludedIDs[assignment.ReviewerID] = true
+			if assignment.ReviewerID == oldReviewerID {
+				assigned = true
+				continue
+			}
+			pr.ReviewerIDs = append(pr.ReviewerIDs, assignment.ReviewerID)
+		}
+		if !assigned {
+			return nil
+		}
 
-	newAssignment := &domain.ReviewerAssignment{
-		PRID:       pr.ID,
-		ReviewerID: newReviewer.ID,
+		team, err := uow.Teams().GetByID(ctx, author.TeamID)
+		if err != nil {
+			return err
+		}
+
+		if err := uow.Assignments().Delete(ctx, pr.ID, oldReviewerID); err != nil {
+			return err
+		}
+		if err := recordEvents(ctx, uow, reviewerRemovedEvent(pr.ID, oldReviewerID, domain.AssignmentReasonDeactivation, actorID)); err != nil {
+			return err
+		}
+		remaining := len(assignments) - 1
+
+		// Замену не ищем, если без выбывшего ревьюера PR уже укомплектован
+		if remaining >= team.MaxReviewers {
+			item.Outcome = OutcomeRemoved
+		} else {
+			candidates, err := u.reviewerService.PickReviewers(ctx, uow, team, excludedIDs, 1)
+			if err != nil {
+				return err
+			}
+			if len(candidates) == 0 {
+				item.Outcome = OutcomeLeftShort
+			} else {
+				newAssignment := &domain.ReviewerAssignment{
+					PRID:       pr.ID,
+					ReviewerID: candidates[0].ID,
+				}
+				if err := uow.Assignments().Create(ctx, newAssignment); err != nil {
+					return err
+				}
+				if err := recordEvents(ctx, uow, reviewerAssignedEvent(pr.ID, candidates[0].ID, domain.AssignmentReasonDeactivation, actorID)); err != nil {
+					return err
+				}
+				item.Outcome = OutcomeReassigned
+				item.NewReviewerID = candidates[0].ID
+				remaining++
+			}
+		}
+		item.Understaffed = remaining < team.MinReviewers
+
+		pr.UpdatedAt = time.Now().UTC()
+		if err := uow.PullRequests().Update(ctx, pr); err != nil {
+			return err
+		}
+
+		if item.Outcome != OutcomeReassigned {
+			return nil
+		}
+		pr.ReviewerIDs = append(pr.ReviewerIDs, item.NewReviewerID)
+		return reviewerReassignedWebhook(ctx, uow, team.ID, pr, oldReviewerID, item.NewReviewerID, domain.AssignmentReasonDeactivation, actorID)
+	})
+	if err != nil {
+		return PRReassignment{}, err
 	}
-	return u.assignmentRepo.Create(newAssignment)
+	return item, nil
 }
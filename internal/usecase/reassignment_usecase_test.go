@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/danonenka/PR-service/internal/domain"
+)
+
+func TestReportErrorHidesInternalDetails(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("update PR: %w", domain.ErrConcurrentModification), "concurrent modification"},
+		{domain.NewNotFoundError("PR"), "not found"},
+		{fmt.Errorf("begin tx: %w", context.DeadlineExceeded), "deadline exceeded"},
+		{errors.New(`pq: relation "reviewer_assignments" does not exist`), "internal error"},
+	}
+	for _, tt := range tests {
+		if got := reportError(tt.err); got != tt.want {
+			t.Errorf("reportError(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
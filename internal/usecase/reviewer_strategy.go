@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+
+	"github.com/danonenka/PR-service/internal/domain"
+)
+
+// ReviewerCandidate — активный участник команды, которого можно назначить
+// ревьюером, вместе с его текущей нагрузкой.
+type ReviewerCandidate struct {
+	User        *domain.User
+	OpenReviews int
+}
+
+// ReviewerSelectionStrategy выбирает до count ревьюеров из кандидатов.
+// Реализации должны быть безопасны для конкурентного использования.
+type ReviewerSelectionStrategy interface {
+	SelectReviewers(teamID string, candidates []ReviewerCandidate, count int) []*domain.User
+}
+
+func NewReviewerSelectionStrategies() map[domain.ReviewerStrategy]ReviewerSelectionStrategy {
+	return map[domain.ReviewerStrategy]ReviewerSelectionStrategy{
+		domain.ReviewerStrategyRandom:           RandomStrategy{},
+		domain.ReviewerStrategyLeastOpenReviews: LeastOpenReviewsStrategy{},
+		domain.ReviewerStrategyRoundRobin:       NewRoundRobinStrategy(),
+	}
+}
+
+type RandomStrategy struct{}
+
+func (RandomStrategy) SelectReviewers(_ string, candidates []ReviewerCandidate, count int) []*domain.User {
+	shuffled := shuffleCandidates(candidates)
+	return takeUsers(shuffled, count)
+}
+
+// LeastOpenReviewsStrategy отдаёт предпочтение ревьюерам с наименьшим числом
+// открытых PR; при равной нагрузке выбор случайный.
+type LeastOpenReviewsStrategy struct{}
+
+func (LeastOpenReviewsStrategy) SelectReviewers(_ string, candidates []ReviewerCandidate, count int) []*domain.User {
+	sorted := shuffleCandidates(candidates)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].OpenReviews < sorted[j].OpenReviews
+	})
+	return takeUsers(sorted, count)
+}
+
+// RoundRobinStrategy обходит участников команды по порядку user_id, начиная
+// со следующего после последнего назначенного. Очередь приблизительная:
+// позиция хранится в памяти процесса и сбрасывается при рестарте, каждая
+// реплика ведёт свою, а сдвигается позиция до фиксации транзакции, так что
+// откат CreatePR или переназначения пропускает ревьюеров.
+type RoundRobinStrategy struct {
+	mu         sync.Mutex
+	lastByTeam map[string]string
+}
+
+func NewRoundRobinStrategy() *RoundRobinStrategy {
+	return &RoundRobinStrategy{lastByTeam: make(map[string]string)}
+}
+
+func (s *RoundRobinStrategy) SelectReviewers(teamID string, candidates []ReviewerCandidate, count int) []*domain.User {
+	if len(candidates) == 0 || count <= 0 {
+		return []*domain.User{}
+	}
+
+	sorted := make([]ReviewerCandidate, len(candidates))
+	copy(sorted, candidates)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].User.ID < sorted[j].User.ID
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	start := 0
+	if last, ok := s.lastByTeam[teamID]; ok {
+		start = sort.Search(len(sorted), func(i int) bool {
+			return sorted[i].User.ID > last
+		}) % len(sorted)
+	}
+
+	if count > len(sorted) {
+		count = len(sorted)
+	}
+	selected := make([]*domain.User, 0, count)
+	for i := 0; i < count; i++ {
+		selected = append(selected, sorted[(start+i)%len(sorted)].User)
+	}
+	s.lastByTeam[teamID] = selected[len(selected)-1].ID
+
+	return selected
+}
+
+func shuffleCandidates(candidates []ReviewerCandidate) []ReviewerCandidate {
+	shuffled := make([]ReviewerCandidate, len(candidates))
+	copy(shuffled, candidates)
+	rand.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	return shuffled
+}
+
+func takeUsers(candidates []ReviewerCandidate, count int) []*domain.User {
+	if count < 0 {
+		count = 0
+	}
+	if count > len(candidates) {
+		count = len(candidates)
+	}
+	users := make([]*domain.User, 0, count)
+	for _, candidate := range candidates[:count] {
+		users = append(users, candidate.User)
+	}
+	return users
+}
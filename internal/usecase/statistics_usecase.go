@@ -1,98 +1,116 @@
 package usecase
 
 import (
+	"context"
+	"time"
+
 	"github.com/danonenka/PR-service/internal/domain"
 )
 
 type StatisticsUsecase struct {
-	prRepo         domain.PullRequestRepository
-	assignmentRepo domain.ReviewerAssignmentRepository
-	userRepo       domain.UserRepository
+	statsRepo domain.StatisticsRepository
+	teamRepo  domain.TeamRepository
 }
 
 func NewStatisticsUsecase(
-	prRepo domain.PullRequestRepository,
-	assignmentRepo domain.ReviewerAssignmentRepository,
-	userRepo domain.UserRepository,
+	statsRepo domain.StatisticsRepository,
+	teamRepo domain.TeamRepository,
 ) *StatisticsUsecase {
 	return &StatisticsUsecase{
-		prRepo:         prRepo,
-		assignmentRepo: assignmentRepo,
-		userRepo:       userRepo,
+		statsRepo: statsRepo,
+		teamRepo:  teamRepo,
 	}
 }
 
+// StatsFilter ограничивает выборку статистики. Пустые поля не фильтруют.
+// From/To применяются к времени создания PR, To не включается.
+type StatsFilter struct {
+	TeamName string
+	From     *time.Time
+	To       *time.Time
+	Status   domain.PRStatus
+}
+
 type UserAssignmentStats struct {
-	UserID      string `json:"userId"`
-	UserName    string `json:"userName"`
-	Assignments int    `json:"assignments"`
+	UserID            string `json:"userId"`
+	UserName          string `json:"userName"`
+	Assignments       int    `json:"assignments"`
+	OpenAssignments   int    `json:"openAssignments"`
+	MergedAssignments int    `json:"mergedAssignments"`
 }
 
 type PRAssignmentStats struct {
-	PRID        string `json:"prId"`
-	PRTitle     string `json:"prTitle"`
-	Assignments int    `json:"assignments"`
+	PRID        string          `json:"prId"`
+	PRTitle     string          `json:"prTitle"`
+	AuthorID    string          `json:"authorId"`
+	Status      domain.PRStatus `json:"status"`
+	Assignments int             `json:"assignments"`
 }
 
-func (u *StatisticsUsecase) GetUserAssignmentStats() ([]*UserAssignmentStats, error) {
-	allPRs, err := u.prRepo.GetAll()
+func (u *StatisticsUsecase) GetUserAssignmentStats(ctx context.Context, filter StatsFilter) ([]*UserAssignmentStats, error) {
+	repoFilter, err := u.repositoryFilter(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 
-	userStatsMap := make(map[string]*UserAssignmentStats)
-
-	for _, pr := range allPRs {
-		assignments, err := u.assignmentRepo.GetByPRID(pr.ID)
-		if err != nil {
-			continue
-		}
-
-		for _, assignment := range assignments {
-			if stats, exists := userStatsMap[assignment.ReviewerID]; exists {
-				stats.Assignments++
-			} else {
-				user, err := u.userRepo.GetByID(assignment.ReviewerID)
-				if err != nil {
-					continue
-				}
-				userStatsMap[assignment.ReviewerID] = &UserAssignmentStats{
-					UserID:      assignment.ReviewerID,
-					UserName:    user.Name,
-					Assignments: 1,
-				}
-			}
-		}
+	rows, err := u.statsRepo.ReviewerStats(ctx, repoFilter)
+	if err != nil {
+		return nil, err
 	}
 
-	stats := make([]*UserAssignmentStats, 0, len(userStatsMap))
-	for _, stat := range userStatsMap {
-		stats = append(stats, stat)
+	// Порядок задаёт репозиторий: самые загруженные ревьюеры идут первыми
+	stats := make([]*UserAssignmentStats, 0, len(rows))
+	for _, row := range rows {
+		stats = append(stats, &UserAssignmentStats{
+			UserID:            row.UserID,
+			UserName:          row.UserName,
+			Assignments:       row.Assignments,
+			OpenAssignments:   row.OpenAssignments,
+			MergedAssignments: row.MergedAssignments,
+		})
 	}
-
 	return stats, nil
 }
 
-func (u *StatisticsUsecase) GetPRAssignmentStats() ([]*PRAssignmentStats, error) {
-	allPRs, err := u.prRepo.GetAll()
+func (u *StatisticsUsecase) GetPRAssignmentStats(ctx context.Context, filter StatsFilter) ([]*PRAssignmentStats, error) {
+	repoFilter, err := u.repositoryFilter(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 
-	stats := make([]*PRAssignmentStats, 0, len(allPRs))
-	for _, pr := range allPRs {
-		assignments, err := u.assignmentRepo.GetByPRID(pr.ID)
-		if err != nil {
-			continue
-		}
+	rows, err := u.statsRepo.PullRequestStats(ctx, repoFilter)
+	if err != nil {
+		return nil, err
+	}
 
+	stats := make([]*PRAssignmentStats, 0, len(rows))
+	for _, row := range rows {
 		stats = append(stats, &PRAssignmentStats{
-			PRID:        pr.ID,
-			PRTitle:     pr.Title,
-			Assignments: len(assignments),
+			PRID:        row.PRID,
+			PRTitle:     row.PRTitle,
+			AuthorID:    row.AuthorID,
+			Status:      row.Status,
+			Assignments: row.Assignments,
 		})
 	}
-
 	return stats, nil
 }
 
+// repositoryFilter переводит имя команды в её ID для запроса к базе.
+func (u *StatisticsUsecase) repositoryFilter(ctx context.Context, filter StatsFilter) (domain.StatsFilter, error) {
+	repoFilter := domain.StatsFilter{
+		From:   filter.From,
+		To:     filter.To,
+		Status: filter.Status,
+	}
+	if filter.TeamName == "" {
+		return repoFilter, nil
+	}
+
+	team, err := u.teamRepo.GetByName(ctx, filter.TeamName)
+	if err != nil {
+		return domain.StatsFilter{}, notFound("team", err)
+	}
+	repoFilter.TeamID = team.ID
+	return repoFilter, nil
+}
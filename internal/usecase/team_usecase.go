@@ -1,101 +1,181 @@
 package usecase
 
 import (
-	"database/sql"
+	"context"
 	"errors"
-	"strings"
 
 	"github.com/danonenka/PR-service/internal/domain"
 	"github.com/google/uuid"
 )
 
 type TeamUsecase struct {
-	teamRepo domain.TeamRepository
-	userRepo domain.UserRepository
+	teamRepo  domain.TeamRepository
+	userRepo  domain.UserRepository
+	txManager domain.TxManager
+	// Настройки ревью для команд, которые создаются в AddTeamWithMembers
+	defaultStrategy domain.ReviewerStrategy
+	minReviewers    int
+	maxReviewers    int
 }
 
-func NewTeamUsecase(teamRepo domain.TeamRepository, userRepo domain.UserRepository) *TeamUsecase {
+func NewTeamUsecase(teamRepo domain.TeamRepository, userRepo domain.UserRepository, txManager domain.TxManager) *TeamUsecase {
 	return &TeamUsecase{
-		teamRepo: teamRepo,
-		userRepo: userRepo,
+		teamRepo:  teamRepo,
+		userRepo:  userRepo,
+		txManager: txManager,
+
+		defaultStrategy: domain.ReviewerStrategyRandom,
+		minReviewers:    domain.DefaultMinReviewers,
+		maxReviewers:    domain.DefaultMaxReviewers,
 	}
 }
 
-func (u *TeamUsecase) CreateTeam(team *domain.Team) error {
-	return u.teamRepo.Create(team)
+// SetDefaults задаёт стратегию и лимиты ревьюеров для новых команд.
+// Существующие команды не меняются.
+func (u *TeamUsecase) SetDefaults(strategy domain.ReviewerStrategy, minReviewers, maxReviewers int) {
+	u.defaultStrategy = strategy
+	u.minReviewers = minReviewers
+	u.maxReviewers = maxReviewers
 }
 
-func (u *TeamUsecase) GetTeamByID(id string) (*domain.Team, error) {
-	return u.teamRepo.GetByID(id)
+func (u *TeamUsecase) CreateTeam(ctx context.Context, team *domain.Team) error {
+	return u.teamRepo.Create(ctx, team)
 }
 
-func (u *TeamUsecase) GetTeamByName(name string) (*domain.Team, error) {
-	return u.teamRepo.GetByName(name)
+func (u *TeamUsecase) GetTeamByID(ctx context.Context, id string) (*domain.Team, error) {
+	return u.teamRepo.GetByID(ctx, id)
 }
 
-func (u *TeamUsecase) GetAllTeams() ([]*domain.Team, error) {
-	return u.teamRepo.GetAll()
+func (u *TeamUsecase) GetTeamByName(ctx context.Context, name string) (*domain.Team, error) {
+	return u.teamRepo.GetByName(ctx, name)
 }
 
-func (u *TeamUsecase) UpdateTeam(team *domain.Team) error {
-	return u.teamRepo.Update(team)
+func (u *TeamUsecase) GetAllTeams(ctx context.Context) ([]*domain.Team, error) {
+	return u.teamRepo.GetAll(ctx)
 }
 
-func (u *TeamUsecase) DeleteTeam(id string) error {
-	return u.teamRepo.Delete(id)
+func (u *TeamUsecase) UpdateTeam(ctx context.Context, team *domain.Team) error {
+	return u.teamRepo.Update(ctx, team)
 }
 
-func (u *TeamUsecase) AddTeamWithMembers(teamName string, members []*domain.User) (*domain.Team, error) {
-	existingTeam, err := u.teamRepo.GetByName(teamName)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			team := &domain.Team{
-				ID:   uuid.New().String(),
-				Name: teamName,
-			}
+func (u *TeamUsecase) DeleteTeam(ctx context.Context, id string) error {
+	return u.teamRepo.Delete(ctx, id)
+}
 
-			if err := u.teamRepo.Create(team); err != nil {
-				if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
-					return nil, errors.New("TEAM_EXISTS")
+// AddTeamWithMembers создаёт команду (или обновляет существующую) вместе с
+// участниками. Пустая strategy оставляет текущую стратегию команды, а для
+// новой команды означает стратегию по умолчанию (SetDefaults).
+func (u *TeamUsecase) AddTeamWithMembers(ctx context.Context, teamName string, strategy domain.ReviewerStrategy, members []*domain.User) (*domain.Team, error) {
+	var result *domain.Team
+	err := u.txManager.WithinTx(ctx, func(uow domain.UnitOfWork) error {
+		existingTeam, err := uow.Teams().GetByName(ctx, teamName)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				if strategy == "" {
+					strategy = u.defaultStrategy
 				}
-				return nil, err
+				team := &domain.Team{
+					ID:               uuid.New().String(),
+					Name:             teamName,
+					ReviewerStrategy: strategy,
+					MinReviewers:     u.minReviewers,
+					MaxReviewers:     u.maxReviewers,
+				}
+
+				if err := uow.Teams().Create(ctx, team); err != nil {
+					// Команду с тем же именем успела создать параллельная транзакция
+					if errors.Is(err, domain.ErrConflict) {
+						return domain.ErrTeamExists
+					}
+					return err
+				}
+				existingTeam = team
+			} else {
+				return err
+			}
+		} else if strategy != "" && strategy != existingTeam.ReviewerStrategy {
+			existingTeam.ReviewerStrategy = strategy
+			if err := uow.Teams().Update(ctx, existingTeam); err != nil {
+				return err
 			}
-			existingTeam = team
-		} else {
-			return nil, err
 		}
-	}
 
-	for _, member := range members {
-		member.TeamID = existingTeam.ID
-		existingUser, err := u.userRepo.GetByID(member.ID)
-		if err == nil && existingUser != nil {
-			existingUser.Name = member.Name
-			existingUser.IsActive = member.IsActive
-			existingUser.TeamID = existingTeam.ID
-			if err := u.userRepo.Update(existingUser); err != nil {
-				return nil, err
-			}
-		} else {
-			if err := u.userRepo.Create(member); err != nil {
-				return nil, err
+		for _, member := range members {
+			member.TeamID = existingTeam.ID
+			existingUser, err := uow.Users().GetByID(ctx, member.ID)
+			if err == nil && existingUser != nil {
+				existingUser.Name = member.Name
+				existingUser.IsActive = member.IsActive
+				existingUser.TeamID = existingTeam.ID
+				if err := uow.Users().Update(ctx, existingUser); err != nil {
+					return err
+				}
+			} else {
+				if err := uow.Users().Create(ctx, member); err != nil {
+					return err
+				}
 			}
 		}
-	}
 
-	return existingTeam, nil
+		result = existingTeam
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
-func (u *TeamUsecase) GetTeamWithMembers(teamName string) (*domain.Team, []*domain.User, error) {
-	team, err := u.teamRepo.GetByName(teamName)
+func (u *TeamUsecase) GetTeamWithMembers(ctx context.Context, teamName string) (*domain.Team, []*domain.User, error) {
+	team, err := u.teamRepo.GetByName(ctx, teamName)
 	if err != nil {
-		return nil, nil, errors.New("team not found")
+		return nil, nil, notFound("team", err)
 	}
 
-	members, err := u.userRepo.GetByTeamID(team.ID)
+	members, err := u.userRepo.GetByTeamID(ctx, team.ID)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	return team, members, nil
 }
+
+// TeamSettings — частичное обновление настроек команды, nil-поля не меняются.
+type TeamSettings struct {
+	ReviewerStrategy  *domain.ReviewerStrategy
+	MinReviewers      *int
+	MaxReviewers      *int
+	RequiredApprovals *int
+}
+
+func (u *TeamUsecase) UpdateTeamSettings(ctx context.Context, teamName string, settings TeamSettings) (*domain.Team, error) {
+	team, err := u.teamRepo.GetByName(ctx, teamName)
+	if err != nil {
+		return nil, notFound("team", err)
+	}
+
+	if settings.ReviewerStrategy != nil {
+		if !settings.ReviewerStrategy.IsValid() {
+			return nil, domain.NewValidationError("invalid team settings")
+		}
+		team.ReviewerStrategy = *settings.ReviewerStrategy
+	}
+	if settings.MinReviewers != nil {
+		team.MinReviewers = *settings.MinReviewers
+	}
+	if settings.MaxReviewers != nil {
+		team.MaxReviewers = *settings.MaxReviewers
+	}
+	if settings.RequiredApprovals != nil {
+		team.RequiredApprovals = *settings.RequiredApprovals
+	}
+	if !domain.ValidReviewerLimits(team.MinReviewers, team.MaxReviewers) ||
+		!domain.ValidRequiredApprovals(team.RequiredApprovals, team.MaxReviewers) {
+		return nil, domain.NewValidationError("invalid team settings")
+	}
+
+	if err := u.teamRepo.Update(ctx, team); err != nil {
+		return nil, err
+	}
+	return team, nil
+}
@@ -1,7 +1,9 @@
 package usecase
 
 import (
-	"errors"
+	"context"
+	"log/slog"
+
 	"github.com/danonenka/PR-service/internal/domain"
 )
 
@@ -19,48 +21,122 @@ func NewUserUsecase(userRepo domain.UserRepository, teamRepo domain.TeamReposito
 	}
 }
 
-func (u *UserUsecase) CreateUser(user *domain.User) error {
-	_, err := u.teamRepo.GetByID(user.TeamID)
+func (u *UserUsecase) CreateUser(ctx context.Context, user *domain.User) error {
+	_, err := u.teamRepo.GetByID(ctx, user.TeamID)
 	if err != nil {
-		return errors.New("team not found")
+		return notFound("team", err)
 	}
-	return u.userRepo.Create(user)
+	return u.userRepo.Create(ctx, user)
+}
+
+func (u *UserUsecase) GetUserByID(ctx context.Context, id string) (*domain.User, error) {
+	return u.userRepo.GetByID(ctx, id)
 }
 
-func (u *UserUsecase) GetUserByID(id string) (*domain.User, error) {
-	return u.userRepo.GetByID(id)
+func (u *UserUsecase) GetUsersByTeamID(ctx context.Context, teamID string) ([]*domain.User, error) {
+	return u.userRepo.GetByTeamID(ctx, teamID)
 }
 
-func (u *UserUsecase) GetUsersByTeamID(teamID string) ([]*domain.User, error) {
-	return u.userRepo.GetByTeamID(teamID)
+func (u *UserUsecase) GetActiveUsersByTeamID(ctx context.Context, teamID string) ([]*domain.User, error) {
+	return u.userRepo.GetActiveByTeamID(ctx, teamID)
 }
 
-func (u *UserUsecase) GetActiveUsersByTeamID(teamID string) ([]*domain.User, error) {
-	return u.userRepo.GetActiveByTeamID(teamID)
+func (u *UserUsecase) UpdateUser(ctx context.Context, user *domain.User) error {
+	return u.userRepo.Update(ctx, user)
 }
 
-func (u *UserUsecase) UpdateUser(user *domain.User) error {
-	return u.userRepo.Update(user)
+type DeactivationResult struct {
+	DeactivatedUserIDs []string
+	// SkippedUserIDs — пользователи, которых нет или которые не состоят в команде
+	SkippedUserIDs []string
+	Report         *ReassignmentReport
 }
 
-func (u *UserUsecase) DeactivateUsers(teamID string, userIDs []string) error {
+// DeactivateUsers деактивирует участников команды и снимает их с открытых PR.
+// Сначала меняется флаг, затем идёт переназначение, чтобы деактивированные
+// пользователи не выбирались в замену друг другу.
+func (u *UserUsecase) DeactivateUsers(ctx context.Context, teamID string, userIDs []string, actorID string) (*DeactivationResult, error) {
+	seen := make(map[string]bool, len(userIDs))
+	uniqueIDs := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		if !seen[id] {
+			seen[id] = true
+			uniqueIDs = append(uniqueIDs, id)
+		}
+	}
+
+	users, err := u.userRepo.GetByIDs(ctx, uniqueIDs)
+	if err != nil {
+		return nil, err
+	}
+	members := make(map[string]bool, len(users))
+	for _, user := range users {
+		if user.TeamID == teamID {
+			members[user.ID] = true
+		}
+	}
+
+	result := &DeactivationResult{
+		DeactivatedUserIDs: make([]string, 0, len(members)),
+		SkippedUserIDs:     make([]string, 0),
+		Report:             &ReassignmentReport{Items: make([]PRReassignment, 0)},
+	}
+	for _, id := range uniqueIDs {
+		if members[id] {
+			result.DeactivatedUserIDs = append(result.DeactivatedUserIDs, id)
+		} else {
+			result.SkippedUserIDs = append(result.SkippedUserIDs, id)
+		}
+	}
+
+	if err := u.userRepo.DeactivateUsers(ctx, teamID, result.DeactivatedUserIDs); err != nil {
+		return nil, err
+	}
+
+	if len(result.SkippedUserIDs) > 0 {
+		slog.InfoContext(ctx, "users skipped on deactivation: not team members",
+			"team_id", teamID, "user_ids", result.SkippedUserIDs)
+	}
+
 	if u.reassignmentUsecase != nil {
-		if err := u.reassignmentUsecase.ReassignDeactivatedReviewers(teamID, userIDs); err != nil {
+		report, err := u.reassignmentUsecase.ReassignDeactivatedReviewers(ctx, teamID, result.DeactivatedUserIDs, actorID)
+		if err != nil {
+			// Пользователи уже деактивированы: повторный запрос дочистит PR
+			slog.ErrorContext(ctx, "reassignment after deactivation failed",
+				"team_id", teamID, "user_ids", result.DeactivatedUserIDs, "error", err)
+			return nil, err
 		}
+		result.Report = report
 	}
-	return u.userRepo.DeactivateUsers(teamID, userIDs)
+
+	return result, nil
 }
 
-func (u *UserUsecase) SetUserIsActive(userID string, isActive bool) (*domain.User, error) {
-	user, err := u.userRepo.GetByID(userID)
+// SetUserIsActive меняет флаг активности. При деактивации пользователь
+// снимается с открытых PR, и отчёт о переназначениях возвращается вторым
+// значением; в остальных случаях отчёт nil.
+func (u *UserUsecase) SetUserIsActive(ctx context.Context, userID string, isActive bool, actorID string) (*domain.User, *ReassignmentReport, error) {
+	user, err := u.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, nil, notFound("user", err)
 	}
 
 	user.IsActive = isActive
-	if err := u.userRepo.Update(user); err != nil {
-		return nil, err
+	if err := u.userRepo.Update(ctx, user); err != nil {
+		return nil, nil, err
+	}
+
+	// Повторная деактивация тоже запускает переназначение: так можно
+	// дочистить PR, которые не удалось переназначить в прошлый раз
+	if isActive || u.reassignmentUsecase == nil {
+		return user, nil, nil
 	}
 
-	return user, nil
+	report, err := u.reassignmentUsecase.ReassignDeactivatedReviewers(ctx, user.TeamID, []string{user.ID}, actorID)
+	if err != nil {
+		slog.ErrorContext(ctx, "reassignment after deactivation failed",
+			"team_id", user.TeamID, "user_id", user.ID, "error", err)
+		return nil, nil, err
+	}
+	return user, report, nil
 }
@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/danonenka/PR-service/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// WebhookPayload — тело запроса, которое получают подписчики вебхуков.
+type WebhookPayload struct {
+	EventID       string                  `json:"event_id"`
+	Type          domain.WebhookEventType `json:"type"`
+	OccurredAt    time.Time               `json:"occurred_at"`
+	TeamID        string                  `json:"team_id"`
+	ActorID       string                  `json:"actor_id,omitempty"`
+	PullRequest   WebhookPullRequest      `json:"pull_request"`
+	ReviewerID    string                  `json:"reviewer_id,omitempty"`
+	OldReviewerID string                  `json:"old_reviewer_id,omitempty"`
+	Reason        domain.AssignmentReason `json:"reason,omitempty"`
+}
+
+type WebhookPullRequest struct {
+	PullRequestID     string   `json:"pull_request_id"`
+	PullRequestName   string   `json:"pull_request_name"`
+	AuthorID          string   `json:"author_id"`
+	Status            string   `json:"status"`
+	AssignedReviewers []string `json:"assigned_reviewers"`
+}
+
+func newWebhookPayload(eventType domain.WebhookEventType, teamID string, pr *domain.PullRequest, actorID string) *WebhookPayload {
+	reviewers := pr.ReviewerIDs
+	if reviewers == nil {
+		reviewers = []string{}
+	}
+	return &WebhookPayload{
+		EventID:    uuid.NewString(),
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		TeamID:     teamID,
+		ActorID:    actorID,
+		PullRequest: WebhookPullRequest{
+			PullRequestID:     pr.ID,
+			PullRequestName:   pr.Title,
+			AuthorID:          pr.AuthorID,
+			Status:            string(pr.Status),
+			AssignedReviewers: reviewers,
+		},
+	}
+}
+
+// enqueueWebhook кладёт событие в outbox для каждой подходящей подписки.
+// Запись идёт в транзакции uow: событие уходит подписчикам, только если
+// изменение зафиксировано, и не теряется при падении сервиса.
+func enqueueWebhook(ctx context.Context, uow domain.UnitOfWork, payload *WebhookPayload) error {
+	subscriptions, err := uow.WebhookSubscriptions().GetMatching(ctx, payload.TeamID, payload.Type)
+	if err != nil || len(subscriptions) == 0 {
+		return err
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	for _, subscription := range subscriptions {
+		delivery := &domain.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			EventID:        payload.EventID,
+			EventType:      payload.Type,
+			Payload:        body,
+		}
+		if err := uow.WebhookDeliveries().Create(ctx, delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reviewerAssignedWebhooks ставит в outbox по событию reviewer.assigned на
+// каждого нового ревьюера. Вызывается, когда pr.ReviewerIDs уже итоговый.
+func reviewerAssignedWebhooks(ctx context.Context, uow domain.UnitOfWork, teamID string, pr *domain.PullRequest, reviewerIDs []string, reason domain.AssignmentReason, actorID string) error {
+	for _, reviewerID := range reviewerIDs {
+		payload := newWebhookPayload(domain.WebhookEventReviewerAssigned, teamID, pr, actorID)
+		payload.ReviewerID = reviewerID
+		payload.Reason = reason
+		if err := enqueueWebhook(ctx, uow, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func reviewerReassignedWebhook(ctx context.Context, uow domain.UnitOfWork, teamID string, pr *domain.PullRequest, oldReviewerID, newReviewerID string, reason domain.AssignmentReason, actorID string) error {
+	payload := newWebhookPayload(domain.WebhookEventReviewerReassigned, teamID, pr, actorID)
+	payload.OldReviewerID = oldReviewerID
+	payload.ReviewerID = newReviewerID
+	payload.Reason = reason
+	return enqueueWebhook(ctx, uow, payload)
+}
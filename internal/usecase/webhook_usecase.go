@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+
+	"github.com/danonenka/PR-service/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type WebhookUsecase struct {
+	subscriptionRepo domain.WebhookSubscriptionRepository
+	deliveryRepo     domain.WebhookDeliveryRepository
+	teamRepo         domain.TeamRepository
+}
+
+func NewWebhookUsecase(
+	subscriptionRepo domain.WebhookSubscriptionRepository,
+	deliveryRepo domain.WebhookDeliveryRepository,
+	teamRepo domain.TeamRepository,
+) *WebhookUsecase {
+	return &WebhookUsecase{
+		subscriptionRepo: subscriptionRepo,
+		deliveryRepo:     deliveryRepo,
+		teamRepo:         teamRepo,
+	}
+}
+
+// WebhookSubscriptionRequest — параметры новой подписки. Пустой TeamName
+// подписывает на события всех команд, пустой EventTypes — на все события.
+// Если Secret не задан, он генерируется.
+type WebhookSubscriptionRequest struct {
+	URL        string
+	Secret     string
+	TeamName   string
+	EventTypes []domain.WebhookEventType
+}
+
+// CreateSubscription регистрирует подписку. Секрет возвращается только
+// здесь: списки подписок его не отдают.
+func (u *WebhookUsecase) CreateSubscription(ctx context.Context, req WebhookSubscriptionRequest) (*domain.WebhookSubscription, error) {
+	target, err := url.Parse(req.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, domain.NewValidationError("invalid webhook url")
+	}
+	for _, eventType := range req.EventTypes {
+		if !eventType.IsValid() {
+			return nil, domain.NewValidationError("unknown event type")
+		}
+	}
+
+	subscription := &domain.WebhookSubscription{
+		ID:         uuid.NewString(),
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: req.EventTypes,
+	}
+	if subscription.EventTypes == nil {
+		subscription.EventTypes = []domain.WebhookEventType{}
+	}
+	if req.TeamName != "" {
+		team, err := u.teamRepo.GetByName(ctx, req.TeamName)
+		if err != nil {
+			return nil, notFound("team", err)
+		}
+		subscription.TeamID = team.ID
+	}
+	if subscription.Secret == "" {
+		secret, err := generateSecret()
+		if err != nil {
+			return nil, err
+		}
+		subscription.Secret = secret
+	}
+
+	if err := u.subscriptionRepo.Create(ctx, subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (u *WebhookUsecase) ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	return u.subscriptionRepo.GetAll(ctx)
+}
+
+// DeleteSubscription удаляет подписку вместе с журналом её доставок.
+func (u *WebhookUsecase) DeleteSubscription(ctx context.Context, id string) error {
+	return notFound("webhook", u.subscriptionRepo.Delete(ctx, id))
+}
+
+// ListDeliveries возвращает последние доставки подписки, новые первыми.
+// Пустой status не фильтрует.
+func (u *WebhookUsecase) ListDeliveries(ctx context.Context, subscriptionID string, status domain.WebhookDeliveryStatus, limit int) ([]*domain.WebhookDelivery, error) {
+	if _, err := u.subscriptionRepo.GetByID(ctx, subscriptionID); err != nil {
+		return nil, notFound("webhook", err)
+	}
+	return u.deliveryRepo.ListBySubscription(ctx, subscriptionID, status, normalizeLimit(limit))
+}
+
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,204 @@
+// Package webhook отправляет подписчикам события из outbox-таблицы
+// webhook_deliveries.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danonenka/PR-service/internal/domain"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// Sign возвращает подпись тела запроса: "sha256=" и HMAC-SHA256 в hex.
+// Получатель считает её тем же секретом и сравнивает с SignatureHeader.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify проверяет подпись в формате Sign. Тот же формат использует GitHub
+// в заголовке X-Hub-Signature-256.
+func Verify(secret string, body []byte, signature string) bool {
+	if secret == "" {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+type Config struct {
+	// BatchSize — сколько доставок забирается за один проход
+	BatchSize int
+	// MaxAttempts — после стольких неудачных попыток доставка помечается FAILED
+	MaxAttempts int
+	// BaseBackoff удваивается с каждой неудачной попыткой, но не выше MaxBackoff
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Timeout — ограничение на один HTTP-запрос к подписчику
+	Timeout time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		BatchSize:   50,
+		MaxAttempts: 8,
+		BaseBackoff: 5 * time.Second,
+		MaxBackoff:  time.Hour,
+		Timeout:     10 * time.Second,
+	}
+}
+
+type Dispatcher struct {
+	deliveries    domain.WebhookDeliveryRepository
+	subscriptions domain.WebhookSubscriptionRepository
+	client        *http.Client
+	config        Config
+	now           func() time.Time
+}
+
+func NewDispatcher(
+	deliveries domain.WebhookDeliveryRepository,
+	subscriptions domain.WebhookSubscriptionRepository,
+	config Config,
+) *Dispatcher {
+	return &Dispatcher{
+		deliveries:    deliveries,
+		subscriptions: subscriptions,
+		client:        &http.Client{Timeout: config.Timeout},
+		config:        config,
+		now:           func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Run разбирает outbox каждые interval, пока не отменён ctx. Отмена
+// прерывает и текущую пачку: недоставленные доставки вернутся в работу,
+// когда истечёт их аренда.
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.DispatchPending(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "webhook dispatch failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchPending отправляет доставки, у которых наступило время попытки,
+// и возвращает, сколько из них обработано. Ошибка сохранения одной попытки
+// не останавливает пачку: остальные отправленные доставки иначе ушли бы
+// повторно, а их результат потерялся бы; ошибки возвращаются вместе.
+func (d *Dispatcher) DispatchPending(ctx context.Context) (int, error) {
+	// Аренда с запасом на таймаут запроса: пока попытка идёт, другой
+	// диспетчер эту доставку не заберёт
+	lease := d.config.Timeout + time.Minute
+	deliveries, err := d.deliveries.ClaimDue(ctx, d.now(), lease, d.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	for _, delivery := range deliveries {
+		d.attempt(ctx, delivery)
+		if err := d.deliveries.SaveAttempt(ctx, delivery); err != nil {
+			slog.ErrorContext(ctx, "webhook attempt not saved",
+				"delivery_id", delivery.ID, "status", delivery.Status, "error", err)
+			errs = append(errs, fmt.Errorf("save delivery %d: %w", delivery.ID, err))
+		}
+	}
+	return len(deliveries), errors.Join(errs...)
+}
+
+func (d *Dispatcher) attempt(ctx context.Context, delivery *domain.WebhookDelivery) {
+	delivery.Attempts++
+	delivery.LastStatusCode = 0
+	delivery.LastError = ""
+
+	subscription, err := d.subscriptions.GetByID(ctx, delivery.SubscriptionID)
+	if err != nil {
+		d.fail(ctx, delivery, fmt.Sprintf("subscription not found: %v", err))
+		return
+	}
+
+	statusCode, err := d.send(ctx, subscription, delivery)
+	delivery.LastStatusCode = statusCode
+	if err != nil {
+		d.fail(ctx, delivery, err.Error())
+		return
+	}
+
+	now := d.now()
+	delivery.Status = domain.WebhookDeliveryDelivered
+	delivery.DeliveredAt = &now
+}
+
+func (d *Dispatcher) send(ctx context.Context, subscription *domain.WebhookSubscription, delivery *domain.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, delivery.Payload))
+	req.Header.Set(EventHeader, string(delivery.EventType))
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// fail планирует повтор с экспоненциальной задержкой или, если попытки
+// исчерпаны, помечает доставку FAILED.
+func (d *Dispatcher) fail(ctx context.Context, delivery *domain.WebhookDelivery, reason string) {
+	delivery.LastError = reason
+	if delivery.Attempts >= d.config.MaxAttempts {
+		delivery.Status = domain.WebhookDeliveryFailed
+		slog.ErrorContext(ctx, "webhook delivery failed permanently",
+			"delivery_id", delivery.ID, "webhook_id", delivery.SubscriptionID, "attempts", delivery.Attempts, "error", reason)
+		return
+	}
+	slog.WarnContext(ctx, "webhook delivery attempt failed",
+		"delivery_id", delivery.ID, "webhook_id", delivery.SubscriptionID, "attempts", delivery.Attempts, "error", reason)
+	delivery.Status = domain.WebhookDeliveryPending
+	delivery.NextAttemptAt = d.now().Add(d.backoff(delivery.Attempts))
+}
+
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.config.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= d.config.MaxBackoff {
+			return d.config.MaxBackoff
+		}
+	}
+	return delay
+}
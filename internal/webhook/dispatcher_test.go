@@ -0,0 +1,243 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danonenka/PR-service/internal/domain"
+)
+
+type fakeSubscriptions struct {
+	byID map[string]*domain.WebhookSubscription
+}
+
+func (f *fakeSubscriptions) Create(_ context.Context, s *domain.WebhookSubscription) error {
+	f.byID[s.ID] = s
+	return nil
+}
+
+func (f *fakeSubscriptions) GetByID(_ context.Context, id string) (*domain.WebhookSubscription, error) {
+	s, ok := f.byID[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return s, nil
+}
+
+func (f *fakeSubscriptions) GetAll(_ context.Context) ([]*domain.WebhookSubscription, error) {
+	return nil, nil
+}
+
+func (f *fakeSubscriptions) Delete(_ context.Context, id string) error {
+	delete(f.byID, id)
+	return nil
+}
+
+func (f *fakeSubscriptions) GetMatching(context.Context, string, domain.WebhookEventType) ([]*domain.WebhookSubscription, error) {
+	return nil, nil
+}
+
+type fakeDeliveries struct {
+	items []*domain.WebhookDelivery
+	// saveErr возвращается из SaveAttempt для доставки с ID failSaveID
+	failSaveID int64
+	saveErr    error
+}
+
+func (f *fakeDeliveries) Create(_ context.Context, d *domain.WebhookDelivery) error {
+	d.ID = int64(len(f.items) + 1)
+	f.items = append(f.items, d)
+	return nil
+}
+
+func (f *fakeDeliveries) ClaimDue(_ context.Context, now time.Time, lease time.Duration, limit int) ([]*domain.WebhookDelivery, error) {
+	var due []*domain.WebhookDelivery
+	for _, d := range f.items {
+		if d.Status == domain.WebhookDeliveryPending && !d.NextAttemptAt.After(now) && len(due) < limit {
+			d.NextAttemptAt = now.Add(lease)
+			copied := *d
+			due = append(due, &copied)
+		}
+	}
+	return due, nil
+}
+
+func (f *fakeDeliveries) SaveAttempt(_ context.Context, d *domain.WebhookDelivery) error {
+	if d.ID == f.failSaveID {
+		return f.saveErr
+	}
+	*f.items[d.ID-1] = *d
+	return nil
+}
+
+func (f *fakeDeliveries) ListBySubscription(context.Context, string, domain.WebhookDeliveryStatus, int) ([]*domain.WebhookDelivery, error) {
+	return f.items, nil
+}
+
+type receivedRequest struct {
+	body      []byte
+	signature string
+	event     string
+}
+
+// newReceiver поднимает получателя, который отвечает кодами из statuses
+// по очереди и запоминает пришедшие запросы.
+func newReceiver(t *testing.T, statuses ...int) (*httptest.Server, func() []receivedRequest) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var received []receivedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, receivedRequest{
+			body:      body,
+			signature: r.Header.Get(SignatureHeader),
+			event:     r.Header.Get(EventHeader),
+		})
+		status := statuses[min(len(received), len(statuses))-1]
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []receivedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]receivedRequest(nil), received...)
+	}
+}
+
+func newTestDispatcher(url string, config Config) (*Dispatcher, *fakeDeliveries, *time.Time) {
+	subscriptions := &fakeSubscriptions{byID: map[string]*domain.WebhookSubscription{
+		"sub-1": {ID: "sub-1", URL: url, Secret: "s3cret"},
+	}}
+	deliveries := &fakeDeliveries{}
+	_ = deliveries.Create(context.Background(), &domain.WebhookDelivery{
+		SubscriptionID: "sub-1",
+		EventID:        "evt-1",
+		EventType:      domain.WebhookEventPRMerged,
+		Payload:        []byte(`{"type":"pr.merged"}`),
+		Status:         domain.WebhookDeliveryPending,
+	})
+
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dispatcher := NewDispatcher(deliveries, subscriptions, config)
+	dispatcher.now = func() time.Time { return clock }
+	return dispatcher, deliveries, &clock
+}
+
+func TestDispatcherSignsPayload(t *testing.T) {
+	server, received := newReceiver(t, http.StatusOK)
+	dispatcher, deliveries, _ := newTestDispatcher(server.URL, DefaultConfig())
+
+	if _, err := dispatcher.DispatchPending(context.Background()); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+
+	requests := received()
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+	if requests[0].signature != Sign("s3cret", requests[0].body) {
+		t.Errorf("signature mismatch: %s", requests[0].signature)
+	}
+	if requests[0].event != string(domain.WebhookEventPRMerged) {
+		t.Errorf("unexpected event header %q", requests[0].event)
+	}
+
+	delivery := deliveries.items[0]
+	if delivery.Status != domain.WebhookDeliveryDelivered || delivery.DeliveredAt == nil || delivery.Attempts != 1 {
+		t.Errorf("unexpected delivery state: %+v", delivery)
+	}
+}
+
+func TestDispatcherRetriesWithBackoff(t *testing.T) {
+	server, received := newReceiver(t, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusOK)
+	config := DefaultConfig()
+	config.BaseBackoff = time.Second
+	dispatcher, deliveries, clock := newTestDispatcher(server.URL, config)
+	delivery := deliveries.items[0]
+
+	for attempt, wantDelay := range []time.Duration{time.Second, 2 * time.Second} {
+		if _, err := dispatcher.DispatchPending(context.Background()); err != nil {
+			t.Fatalf("dispatch: %v", err)
+		}
+		if delivery.Status != domain.WebhookDeliveryPending || delivery.LastStatusCode != http.StatusInternalServerError {
+			t.Fatalf("attempt %d: unexpected delivery state: %+v", attempt+1, delivery)
+		}
+		if got := delivery.NextAttemptAt.Sub(*clock); got != wantDelay {
+			t.Fatalf("attempt %d: next attempt in %v, want %v", attempt+1, got, wantDelay)
+		}
+
+		// До наступления времени повтора доставка не забирается
+		if n, _ := dispatcher.DispatchPending(context.Background()); n != 0 {
+			t.Fatalf("attempt %d: delivery retried before backoff elapsed", attempt+1)
+		}
+		*clock = clock.Add(wantDelay)
+	}
+
+	if _, err := dispatcher.DispatchPending(context.Background()); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if delivery.Status != domain.WebhookDeliveryDelivered || delivery.Attempts != 3 {
+		t.Errorf("unexpected delivery state: %+v", delivery)
+	}
+	if len(received()) != 3 {
+		t.Errorf("expected 3 requests, got %d", len(received()))
+	}
+}
+
+func TestDispatcherGivesUpAfterMaxAttempts(t *testing.T) {
+	server, received := newReceiver(t, http.StatusBadGateway)
+	config := DefaultConfig()
+	config.MaxAttempts = 2
+	dispatcher, deliveries, clock := newTestDispatcher(server.URL, config)
+	delivery := deliveries.items[0]
+
+	for i := 0; i < 3; i++ {
+		if _, err := dispatcher.DispatchPending(context.Background()); err != nil {
+			t.Fatalf("dispatch: %v", err)
+		}
+		*clock = clock.Add(time.Hour)
+	}
+
+	if delivery.Status != domain.WebhookDeliveryFailed || delivery.Attempts != 2 {
+		t.Errorf("unexpected delivery state: %+v", delivery)
+	}
+	if len(received()) != 2 {
+		t.Errorf("expected 2 requests, got %d", len(received()))
+	}
+}
+
+func TestDispatcherSavesRestOfBatchAfterSaveError(t *testing.T) {
+	server, received := newReceiver(t, http.StatusOK)
+	dispatcher, deliveries, _ := newTestDispatcher(server.URL, DefaultConfig())
+	_ = deliveries.Create(context.Background(), &domain.WebhookDelivery{
+		SubscriptionID: "sub-1",
+		EventID:        "evt-2",
+		EventType:      domain.WebhookEventPRMerged,
+		Payload:        []byte(`{"type":"pr.merged"}`),
+		Status:         domain.WebhookDeliveryPending,
+	})
+	deliveries.failSaveID = 1
+	deliveries.saveErr = errors.New("connection reset")
+
+	n, err := dispatcher.DispatchPending(context.Background())
+	if !errors.Is(err, deliveries.saveErr) {
+		t.Fatalf("expected save error, got %v", err)
+	}
+	if n != 2 || len(received()) != 2 {
+		t.Fatalf("expected whole batch sent, got n=%d, %d requests", n, len(received()))
+	}
+	if deliveries.items[1].Status != domain.WebhookDeliveryDelivered {
+		t.Errorf("second delivery not saved: %+v", deliveries.items[1])
+	}
+}
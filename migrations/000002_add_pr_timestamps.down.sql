@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_pull_requests_created_at;
+
+ALTER TABLE pull_requests
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS merged_at,
+    DROP COLUMN IF EXISTS created_at;
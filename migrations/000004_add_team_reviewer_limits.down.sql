@@ -0,0 +1,6 @@
+ALTER TABLE teams
+    DROP CONSTRAINT IF EXISTS chk_teams_reviewer_limits;
+
+ALTER TABLE teams
+    DROP COLUMN IF EXISTS max_reviewers,
+    DROP COLUMN IF EXISTS min_reviewers;
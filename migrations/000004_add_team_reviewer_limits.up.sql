@@ -0,0 +1,7 @@
+ALTER TABLE teams
+    ADD COLUMN IF NOT EXISTS min_reviewers INTEGER NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS max_reviewers INTEGER NOT NULL DEFAULT 2;
+
+ALTER TABLE teams
+    ADD CONSTRAINT chk_teams_reviewer_limits
+    CHECK (min_reviewers >= 0 AND max_reviewers >= min_reviewers AND max_reviewers <= 10);
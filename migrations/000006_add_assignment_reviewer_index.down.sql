@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_reviewer_assignments_reviewer_pr;
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_reviewer_assignments_reviewer_pr ON reviewer_assignments(reviewer_id, pr_id);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_pull_requests_updated_at_id;
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_pull_requests_updated_at_id ON pull_requests(updated_at, id);
@@ -0,0 +1,9 @@
+ALTER TABLE teams
+    DROP CONSTRAINT IF EXISTS chk_teams_required_approvals,
+    DROP COLUMN IF EXISTS required_approvals;
+
+ALTER TABLE reviewer_assignments
+    DROP CONSTRAINT IF EXISTS chk_reviewer_assignments_state,
+    DROP COLUMN IF EXISTS reviewed_at,
+    DROP COLUMN IF EXISTS assigned_at,
+    DROP COLUMN IF EXISTS state;
@@ -0,0 +1,15 @@
+ALTER TABLE reviewer_assignments
+    ADD COLUMN IF NOT EXISTS state VARCHAR(32) NOT NULL DEFAULT 'PENDING',
+    ADD COLUMN IF NOT EXISTS assigned_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMPTZ;
+
+ALTER TABLE reviewer_assignments
+    ADD CONSTRAINT chk_reviewer_assignments_state
+    CHECK (state IN ('PENDING', 'APPROVED', 'CHANGES_REQUESTED', 'COMMENTED'));
+
+ALTER TABLE teams
+    ADD COLUMN IF NOT EXISTS required_approvals INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE teams
+    ADD CONSTRAINT chk_teams_required_approvals
+    CHECK (required_approvals >= 0 AND required_approvals <= max_reviewers);
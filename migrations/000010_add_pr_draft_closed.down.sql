@@ -0,0 +1,3 @@
+ALTER TABLE pull_requests
+    DROP CONSTRAINT IF EXISTS chk_pull_requests_status,
+    DROP COLUMN IF EXISTS closed_at;
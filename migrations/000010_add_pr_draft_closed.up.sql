@@ -0,0 +1,6 @@
+ALTER TABLE pull_requests
+    ADD COLUMN IF NOT EXISTS closed_at TIMESTAMPTZ;
+
+ALTER TABLE pull_requests
+    ADD CONSTRAINT chk_pull_requests_status
+    CHECK (status IN ('DRAFT', 'OPEN', 'MERGED', 'CLOSED'));
@@ -0,0 +1,25 @@
+-- Журнал изменений PR. Внешних ключей нет намеренно: история должна
+-- переживать удаление пользователей и PR.
+CREATE TABLE IF NOT EXISTS pr_events (
+    id BIGSERIAL PRIMARY KEY,
+    pr_id VARCHAR(255) NOT NULL,
+    event_type VARCHAR(32) NOT NULL,
+    actor_id VARCHAR(255),
+    reviewer_id VARCHAR(255),
+    reason VARCHAR(32),
+    from_status VARCHAR(50),
+    to_status VARCHAR(50),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_events_pr_id ON pr_events(pr_id, id);
+
+CREATE OR REPLACE FUNCTION pr_events_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'pr_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_pr_events_immutable
+    BEFORE UPDATE OR DELETE ON pr_events
+    FOR EACH ROW EXECUTE FUNCTION pr_events_immutable();
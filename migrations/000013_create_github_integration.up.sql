@@ -0,0 +1,19 @@
+-- Соответствие логинов во внешней системе (Git-хостинге) пользователям сервиса
+CREATE TABLE IF NOT EXISTS identity_mappings (
+    provider VARCHAR(32) NOT NULL,
+    external_login VARCHAR(255) NOT NULL,
+    user_id VARCHAR(255) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (provider, external_login)
+);
+
+CREATE INDEX IF NOT EXISTS idx_identity_mappings_user_id ON identity_mappings(user_id);
+
+-- Принятые входящие вебхуки: повторная доставка с тем же id не обрабатывается
+CREATE TABLE IF NOT EXISTS inbound_deliveries (
+    provider VARCHAR(32) NOT NULL,
+    delivery_id VARCHAR(255) NOT NULL,
+    event VARCHAR(64) NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (provider, delivery_id)
+);
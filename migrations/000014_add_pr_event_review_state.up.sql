@@ -0,0 +1,2 @@
+ALTER TABLE pr_events
+    ADD COLUMN IF NOT EXISTS review_state VARCHAR(32);
@@ -0,0 +1,8 @@
+// Package migrations встраивает SQL-миграции в бинарник. Файлы названы в
+// формате golang-migrate: <версия>_<название>.(up|down).sql.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS